@@ -2,10 +2,12 @@ package bot
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"valorant-app/config"
+	"valorant-app/models"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -23,7 +25,11 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 	bot.Debug = true
 	log.Printf("Authorized on account %s", bot.Self.UserName)
 
-	return &Bot{API: bot}, nil
+	b := &Bot{API: bot}
+	b.registerCommands()
+	purgeExpiredDialogs()
+
+	return b, nil
 }
 
 func (b *Bot) SetWebhook(webhookURL string) error {
@@ -84,6 +90,7 @@ func (b *Bot) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Долгие команды вроде /sync выполняются в фоне, поэтому Telegram получает ответ сразу
 	if update.Message != nil {
 		b.handleMessage(update.Message)
 	}
@@ -93,14 +100,23 @@ func (b *Bot) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// botOrigin источник действий, выполненных через бота, для журнала
+func botOrigin(requestID string) models.RequestOrigin {
+	return models.RequestOrigin{Source: models.AuditSourceBot, RequestID: requestID}
+}
+
+// messageOrigin источник действия, вызванного сообщением
+func messageOrigin(message *tgbotapi.Message) models.RequestOrigin {
+	return botOrigin(fmt.Sprintf("msg:%d:%d", message.Chat.ID, message.MessageID))
+}
+
 func (b *Bot) handleMessage(message *tgbotapi.Message) {
-	if message.Text == "/start" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Добро пожаловать! Используйте команды для управления командами.")
-		b.API.Send(msg)
+	if message.IsCommand() {
+		b.handleCommand(message)
+		return
 	}
-}
 
-func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
-	// Handle callback queries here
-	log.Printf("Callback query: %s", callback.Data)
+	if message.From != nil && message.Text != "" {
+		b.handleDialogInput(message)
+	}
 }
@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"valorant-app/models"
+	"valorant-app/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxCallbackDataLength ограничение Telegram на размер callback data
+const maxCallbackDataLength = 64
+
+// callbackAction тип действия, закодированный в callback data
+type callbackAction string
+
+// Действия inline-кнопок
+const (
+	actionCancel        callbackAction = "x"  // закрыть диалог
+	actionTeamsPage     callbackAction = "tp" // страница списка команд: регион, только набор, ID последней команды
+	actionJoinTeam      callbackAction = "tj" // отправить заявку на вступление в команду: team_id
+	actionLeaveConfirm  callbackAction = "lc" // подтвердить выход из команды: team_id
+	actionAssignMembers callbackAction = "am" // вернуться к выбору участника: team_id
+	actionAssignMember  callbackAction = "au" // выбран участник: team_id, user_id
+	actionAssignRole    callbackAction = "ar" // выбрана роль: team_id, user_id, role_id
+	actionJoinApprove   callbackAction = "ja" // одобрить заявку: request_id
+	actionJoinReject    callbackAction = "jr" // отклонить заявку: request_id
+	actionJoinWithdraw  callbackAction = "jw" // отозвать свою заявку: request_id
+	actionTransferOwner callbackAction = "to" // подтвердить передачу команды: team_id, user_id
+	actionDisband       callbackAction = "dt" // подтвердить роспуск команды: team_id
+	actionSwitchTeam    callbackAction = "ts" // выбрать текущую команду: team_id
+)
+
+// callbackData типизированное содержимое callback data: действие и числовые аргументы.
+// Кодируется как "action:arg1:arg2".
+type callbackData struct {
+	Action callbackAction
+	Args   []uint
+}
+
+// newCallback создает callback data для действия
+func newCallback(action callbackAction, args ...uint) callbackData {
+	return callbackData{Action: action, Args: args}
+}
+
+// Encode кодирует callback data в строку для inline-кнопки
+func (d callbackData) Encode() string {
+	parts := make([]string, 0, len(d.Args)+1)
+	parts = append(parts, string(d.Action))
+	for _, arg := range d.Args {
+		parts = append(parts, strconv.FormatUint(uint64(arg), 10))
+	}
+
+	encoded := strings.Join(parts, ":")
+	if len(encoded) > maxCallbackDataLength {
+		// Такого не должно происходить: аргументы — только идентификаторы
+		log.Printf("Callback data too long: %s", encoded)
+	}
+	return encoded
+}
+
+// Arg возвращает i-й аргумент или 0, если его нет
+func (d callbackData) Arg(i int) uint {
+	if i < len(d.Args) {
+		return d.Args[i]
+	}
+	return 0
+}
+
+// parseCallbackData разбирает строку callback data
+func parseCallbackData(value string) (callbackData, error) {
+	parts := strings.Split(value, ":")
+	if parts[0] == "" {
+		return callbackData{}, errors.New("empty callback action")
+	}
+
+	data := callbackData{Action: callbackAction(parts[0])}
+	for _, part := range parts[1:] {
+		arg, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return callbackData{}, fmt.Errorf("invalid callback argument %q", part)
+		}
+		data.Args = append(data.Args, uint(arg))
+	}
+	return data, nil
+}
+
+// button создает inline-кнопку с типизированной callback data
+func button(text string, data callbackData) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, data.Encode())
+}
+
+// callbackContext данные одного нажатия inline-кнопки
+type callbackContext struct {
+	query    *tgbotapi.CallbackQuery
+	data     callbackData
+	lang     string
+	user     *models.User
+	answered bool
+}
+
+// callbackHandlers диспетчер callback-запросов по действию
+var callbackHandlers = map[callbackAction]func(b *Bot, ctx *callbackContext){
+	actionCancel:        (*Bot).cbCancel,
+	actionTeamsPage:     (*Bot).cbTeamsPage,
+	actionJoinTeam:      (*Bot).cbJoinTeam,
+	actionLeaveConfirm:  (*Bot).cbLeaveConfirm,
+	actionAssignMembers: (*Bot).cbAssignMembers,
+	actionAssignMember:  (*Bot).cbAssignMember,
+	actionAssignRole:    (*Bot).cbAssignRole,
+	actionJoinApprove:   (*Bot).cbJoinApprove,
+	actionJoinReject:    (*Bot).cbJoinReject,
+	actionJoinWithdraw:  (*Bot).cbJoinWithdraw,
+	actionTransferOwner: (*Bot).cbTransferOwner,
+	actionDisband:       (*Bot).cbDisband,
+	actionSwitchTeam:    (*Bot).cbSwitchTeam,
+}
+
+func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
+	ctx := &callbackContext{query: callback, lang: languageOf(callback.From)}
+	// Telegram показывает индикатор загрузки, пока на callback не ответили
+	defer func() {
+		if !ctx.answered {
+			b.answer(ctx, "")
+		}
+	}()
+
+	data, err := parseCallbackData(callback.Data)
+	if err != nil {
+		log.Printf("Invalid callback data %q: %v", callback.Data, err)
+		return
+	}
+	ctx.data = data
+
+	handler, ok := callbackHandlers[data.Action]
+	if !ok || callback.Message == nil {
+		log.Printf("Unhandled callback query: %s", callback.Data)
+		return
+	}
+
+	user, err := services.FindUserByTelegramID(callback.From.ID)
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+	user.Origin = botOrigin(fmt.Sprintf("cb:%s", callback.ID))
+	ctx.user = user
+
+	handler(b, ctx)
+}
+
+// answer отвечает на callback-запрос всплывающим текстом
+func (b *Bot) answer(ctx *callbackContext, text string) {
+	ctx.answered = true
+	if _, err := b.API.Request(tgbotapi.NewCallback(ctx.query.ID, text)); err != nil {
+		log.Printf("Failed to answer callback query: %v", err)
+	}
+}
+
+// answerError отвечает на callback-запрос локализованным текстом ошибки
+func (b *Bot) answerError(ctx *callbackContext, err error) {
+	if errors.Is(err, services.ErrUserNotFound) {
+		b.answer(ctx, tr(ctx.lang, "not_registered"))
+		return
+	}
+	b.answer(ctx, errorText(ctx.lang, err))
+}
+
+// edit заменяет текст и клавиатуру сообщения с нажатой кнопкой
+func (b *Bot) edit(ctx *callbackContext, text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	message := ctx.query.Message
+	var edit tgbotapi.EditMessageTextConfig
+	if markup != nil {
+		edit = tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, text, *markup)
+	} else {
+		edit = tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, text)
+	}
+
+	if _, err := b.API.Send(edit); err != nil {
+		log.Printf("Failed to edit message %d: %v", message.MessageID, err)
+	}
+}
@@ -0,0 +1,729 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"valorant-app/models"
+	"valorant-app/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// commandContext данные одного вызова команды
+type commandContext struct {
+	message *tgbotapi.Message
+	args    string
+	lang    string
+	user    *models.User // nil для команд, не требующих регистрации
+}
+
+// command описание команды бота
+type command struct {
+	handler      func(b *Bot, ctx *commandContext)
+	requiresUser bool
+	description  string
+}
+
+// commands маршрутизатор команд бота
+var commands = map[string]command{
+	"start":      {(*Bot).cmdStart, false, "Начать работу"},
+	"help":       {(*Bot).cmdHelp, false, "Список команд"},
+	"register":   {(*Bot).cmdRegister, false, "Зарегистрироваться"},
+	"createteam": {(*Bot).cmdCreateTeam, true, "Создать команду"},
+	"join":       {(*Bot).cmdJoin, true, "Вступить в команду"},
+	"teams":      {(*Bot).cmdTeams, true, "Поиск команд"},
+	"invite":     {(*Bot).cmdInvite, true, "Пригласить в команду"},
+	"leave":      {(*Bot).cmdLeave, true, "Покинуть команду"},
+	"myteam":     {(*Bot).cmdMyTeam, true, "Моя команда"},
+	"roles":      {(*Bot).cmdRoles, true, "Роли команды"},
+	"assign":     {(*Bot).cmdAssign, true, "Назначить роль"},
+	"kick":       {(*Bot).cmdKick, true, "Исключить участника"},
+	"slot":       {(*Bot).cmdSlot, true, "Позиция участника в составе"},
+	"myrole":     {(*Bot).cmdMyRole, true, "Моя игровая роль и агенты"},
+	"transfer":   {(*Bot).cmdTransfer, true, "Передать команду"},
+	"disband":    {(*Bot).cmdDisband, true, "Распустить команду"},
+	"audit":      {(*Bot).cmdAudit, true, "Журнал команды"},
+	"link":       {(*Bot).cmdLink, true, "Привязать аккаунт Valorant"},
+	"sync":       {(*Bot).cmdSync, true, "Синхронизировать данные Valorant"},
+	"stats":      {(*Bot).cmdStats, true, "Статистика"},
+	"cancel":     {(*Bot).cmdCancel, false, "Отменить текущий диалог"},
+}
+
+// commandOrder порядок команд в меню Telegram
+var commandOrder = []string{
+	"start", "help", "register", "createteam", "join", "teams", "invite", "leave",
+	"myteam", "roles", "assign", "kick", "slot", "myrole", "transfer", "disband", "audit", "link", "sync", "stats", "cancel",
+}
+
+// handleCommand находит и выполняет команду из сообщения
+func (b *Bot) handleCommand(message *tgbotapi.Message) {
+	ctx := &commandContext{
+		message: message,
+		args:    strings.TrimSpace(message.CommandArguments()),
+		lang:    languageOf(message.From),
+	}
+
+	cmd, ok := commands[message.Command()]
+	if !ok {
+		b.reply(ctx, tr(ctx.lang, "unknown_command"))
+		return
+	}
+
+	if cmd.requiresUser {
+		user, err := services.FindUserByTelegramID(message.From.ID)
+		if err != nil {
+			if errors.Is(err, services.ErrUserNotFound) {
+				b.reply(ctx, tr(ctx.lang, "not_registered"))
+			} else {
+				b.replyError(ctx, err)
+			}
+			return
+		}
+		user.Origin = messageOrigin(message)
+		ctx.user = user
+	}
+
+	cmd.handler(b, ctx)
+}
+
+// registerCommands публикует список команд в меню Telegram
+func (b *Bot) registerCommands() {
+	botCommands := make([]tgbotapi.BotCommand, 0, len(commandOrder))
+	for _, name := range commandOrder {
+		botCommands = append(botCommands, tgbotapi.BotCommand{Command: name, Description: commands[name].description})
+	}
+
+	if _, err := b.API.Request(tgbotapi.NewSetMyCommands(botCommands...)); err != nil {
+		log.Printf("Failed to register bot commands: %v", err)
+	}
+}
+
+func (b *Bot) cmdStart(ctx *commandContext) {
+	// Deep link t.me/<bot>?start=<token> приходит как /start <token>
+	if ctx.args != "" {
+		b.redeemInvitation(ctx, ctx.args)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "welcome")+"\n\n"+tr(ctx.lang, "help"))
+}
+
+func (b *Bot) cmdHelp(ctx *commandContext) {
+	b.reply(ctx, tr(ctx.lang, "help"))
+}
+
+func (b *Bot) cmdRegister(ctx *commandContext) {
+	from := ctx.message.From
+	user, err := services.RegisterUser(from.ID, from.UserName, from.FirstName, from.LastName, from.LanguageCode)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "registered", user.FirstName))
+}
+
+func (b *Bot) cmdCreateTeam(ctx *commandContext) {
+	// Без аргументов спрашиваем название и описание по шагам
+	if ctx.args == "" {
+		b.startDialog(ctx, dialogCreateTeam)
+		return
+	}
+
+	name, description, _ := strings.Cut(ctx.args, "|")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		b.reply(ctx, tr(ctx.lang, "usage_createteam"))
+		return
+	}
+
+	team, err := services.CreateTeam(ctx.user, name, strings.TrimSpace(description))
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "team_created", team.Name, team.ID))
+}
+
+func (b *Bot) cmdJoin(ctx *commandContext) {
+	// Без аргументов показываем список команд с кнопками
+	if ctx.args == "" {
+		b.cmdTeams(ctx)
+		return
+	}
+
+	b.redeemInvitation(ctx, ctx.args)
+}
+
+// cmdTeams список команд с подачей заявки: /teams [регион] [часть названия]
+func (b *Bot) cmdTeams(ctx *commandContext) {
+	var state teamsBrowser
+	args := strings.Fields(ctx.args)
+	if len(args) > 0 {
+		for i, region := range models.ValorantRegions {
+			if strings.EqualFold(args[0], region) {
+				state.Region = uint(i + 1)
+				args = args[1:]
+				break
+			}
+		}
+	}
+	state.Search = strings.Join(args, " ")
+
+	text, markup, err := teamsPage(ctx.lang, state)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+	b.replyWithKeyboard(ctx, text, markup)
+}
+
+// redeemInvitation регистрирует пользователя (если нужно) и вступает в команду по приглашению
+func (b *Bot) redeemInvitation(ctx *commandContext, token string) {
+	from := ctx.message.From
+	user, err := services.RegisterUser(from.ID, from.UserName, from.FirstName, from.LastName, from.LanguageCode)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+	user.Origin = messageOrigin(ctx.message)
+
+	team, err := services.RedeemInvitation(user, token)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "team_joined", team.Name))
+}
+
+func (b *Bot) cmdInvite(ctx *commandContext) {
+	maxUses := 1
+	if ctx.args != "" {
+		uses, err := strconv.Atoi(ctx.args)
+		if err != nil || uses < 0 {
+			b.reply(ctx, tr(ctx.lang, "usage_invite"))
+			return
+		}
+		maxUses = uses
+	}
+
+	teamID, ok := b.requireTeamPermission(ctx, models.PermissionInviteMembers)
+	if !ok {
+		return
+	}
+
+	invitation, err := services.CreateInvitation(ctx.user, teamID, services.InvitationOptions{MaxUses: maxUses})
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "invite_created", invitation.Link, invitation.ExpiresAt.Format("02.01.2006 15:04")))
+}
+
+func (b *Bot) cmdLeave(ctx *commandContext) {
+	team, ok := b.currentTeam(ctx)
+	if !ok {
+		return
+	}
+
+	if team.CreatedBy == ctx.user.ID {
+		b.replyError(ctx, services.ErrOwnerCannotLeave)
+		return
+	}
+
+	// Выход подтверждается кнопкой
+	b.replyWithKeyboard(ctx, tr(ctx.lang, "leave_confirm", team.Name), leaveConfirmKeyboard(ctx.lang, team.ID))
+}
+
+func (b *Bot) cmdMyTeam(ctx *commandContext) {
+	team, ok := b.currentTeam(ctx)
+	if !ok {
+		return
+	}
+
+	roster, err := services.GetRoster(team.ID)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "team_info", team.Name, team.ID, team.Description, len(team.Members), rosterText(ctx.lang, roster))
+
+	// Участнику нескольких команд предлагаем переключиться
+	memberships, err := services.ListUserMemberships(ctx.user.ID)
+	if err != nil || len(memberships) < 2 {
+		b.reply(ctx, text)
+		return
+	}
+	b.replyWithKeyboard(ctx, text+"\n\n"+tr(ctx.lang, "team_switch_hint"), switchTeamKeyboard(memberships, team.ID))
+}
+
+func (b *Bot) cmdRoles(ctx *commandContext) {
+	teamID, ok := b.requireTeamPermission(ctx, models.PermissionViewMembers)
+	if !ok {
+		return
+	}
+
+	roles, err := services.GetTeamRoles(teamID)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+	if len(roles) == 0 {
+		b.reply(ctx, tr(ctx.lang, "no_roles"))
+		return
+	}
+
+	lines := make([]string, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, permission.Name)
+		}
+		permissionList := tr(ctx.lang, "no_perms")
+		if len(permissions) > 0 {
+			permissionList = strings.Join(permissions, ", ")
+		}
+		lines = append(lines, tr(ctx.lang, "role_line", role.Name, permissionList))
+	}
+
+	b.reply(ctx, tr(ctx.lang, "roles_header", strings.Join(lines, "\n")))
+}
+
+func (b *Bot) cmdAssign(ctx *commandContext) {
+	args := strings.Fields(ctx.args)
+	if len(args) != 0 && len(args) != 2 {
+		b.reply(ctx, tr(ctx.lang, "usage_assign"))
+		return
+	}
+
+	teamID, ok := b.requireTeamPermission(ctx, models.PermissionManageRoles)
+	if !ok {
+		return
+	}
+
+	// Без аргументов предлагаем выбрать участника и роль кнопками
+	if len(args) == 0 {
+		text, markup, err := assignMembersKeyboard(ctx.lang, teamID)
+		if err != nil {
+			b.replyError(ctx, err)
+			return
+		}
+		b.replyWithKeyboard(ctx, text, markup)
+		return
+	}
+
+	target, err := findMember(teamID, args[0])
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	role, err := services.FindTeamRoleByName(teamID, args[1])
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	if err := services.AssignRole(ctx.user, teamID, target.ID, role.ID); err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "role_granted", role.Name, displayName(target)))
+}
+
+func (b *Bot) cmdKick(ctx *commandContext) {
+	args := strings.Fields(ctx.args)
+	if len(args) != 1 {
+		b.reply(ctx, tr(ctx.lang, "usage_kick"))
+		return
+	}
+
+	teamID, ok := b.requireTeamPermission(ctx, models.PermissionKickMembers)
+	if !ok {
+		return
+	}
+
+	target, err := findMember(teamID, args[0])
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	if _, err := services.KickMember(ctx.user, teamID, target.ID); err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "member_kicked", displayName(target)))
+}
+
+func (b *Bot) cmdTransfer(ctx *commandContext) {
+	args := strings.Fields(ctx.args)
+	if len(args) != 1 {
+		b.reply(ctx, tr(ctx.lang, "usage_transfer"))
+		return
+	}
+
+	team, ok := b.currentTeam(ctx)
+	if !ok {
+		return
+	}
+	if team.CreatedBy != ctx.user.ID {
+		b.replyError(ctx, services.ErrNotTeamOwner)
+		return
+	}
+
+	target, err := findMember(team.ID, args[0])
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	// Передача подтверждается кнопкой
+	b.replyWithKeyboard(ctx, tr(ctx.lang, "transfer_confirm", team.Name, displayName(target)), transferConfirmKeyboard(ctx.lang, team.ID, target.ID))
+}
+
+func (b *Bot) cmdDisband(ctx *commandContext) {
+	team, ok := b.currentTeam(ctx)
+	if !ok {
+		return
+	}
+	if team.CreatedBy != ctx.user.ID {
+		b.replyError(ctx, services.ErrNotTeamOwner)
+		return
+	}
+
+	// Роспуск подтверждается кнопкой
+	b.replyWithKeyboard(ctx, tr(ctx.lang, "disband_confirm", team.Name, len(team.Members)), disbandConfirmKeyboard(ctx.lang, team.ID))
+}
+
+// auditPageSize сколько последних записей журнала показывает /audit по умолчанию
+const auditPageSize = 10
+
+func (b *Bot) cmdAudit(ctx *commandContext) {
+	limit := auditPageSize
+	if ctx.args != "" {
+		n, err := strconv.Atoi(ctx.args)
+		if err != nil || n <= 0 || n > services.MaxAuditLimit {
+			b.reply(ctx, tr(ctx.lang, "usage_audit", services.MaxAuditLimit))
+			return
+		}
+		limit = n
+	}
+
+	teamID, ok := b.requireTeamPermission(ctx, models.PermissionManageTeam)
+	if !ok {
+		return
+	}
+
+	events, _, err := services.ListAuditEvents(teamID, services.AuditFilter{Limit: limit})
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+	if len(events) == 0 {
+		b.reply(ctx, tr(ctx.lang, "audit_empty"))
+		return
+	}
+
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		actor := fmt.Sprintf("#%d", event.ActorID)
+		if event.Actor != nil {
+			actor = displayName(event.Actor)
+		}
+		lines = append(lines, fmt.Sprintf("%s · %s · %s · %s #%d",
+			event.CreatedAt.Format("02.01 15:04"), actor, event.Action, event.TargetType, event.TargetID))
+	}
+
+	b.reply(ctx, tr(ctx.lang, "audit_header", len(events), strings.Join(lines, "\n")))
+}
+
+func (b *Bot) cmdLink(ctx *commandContext) {
+	// Без аргументов спрашиваем имя, тег и регион по шагам
+	if ctx.args == "" {
+		b.startDialog(ctx, dialogLinkValorant)
+		return
+	}
+
+	usage := tr(ctx.lang, "usage_link", strings.Join(models.ValorantRegions, ", "))
+
+	args := strings.Fields(ctx.args)
+	if len(args) != 2 {
+		b.reply(ctx, usage)
+		return
+	}
+
+	gameName, tag, ok := strings.Cut(args[0], "#")
+	region := strings.ToLower(args[1])
+	if !ok || gameName == "" || tag == "" || !models.IsValorantRegion(region) {
+		b.reply(ctx, usage)
+		return
+	}
+
+	player, err := services.LinkValorantPlayer(ctx.user, gameName, tag, region)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "player_linked", player.GameName, player.Tag, player.Region))
+}
+
+// botSyncTimeout предельное время /sync
+const botSyncTimeout = 90 * time.Second
+
+// cmdSync запускает синхронизацию в отдельной горутине и сразу возвращается, чтобы долгая синхронизация
+// не задерживала обновления других чатов в режиме polling и ответ на webhook-запрос Telegram.
+// Результат приходит отдельным сообщением.
+func (b *Bot) cmdSync(ctx *commandContext) {
+	b.reply(ctx, tr(ctx.lang, "sync_started"))
+
+	// Синхронизация не привязана к обновлению Telegram: ответ на webhook уходит до ее окончания
+	syncCtx, cancel := context.WithTimeout(context.Background(), botSyncTimeout)
+	go func() {
+		defer cancel()
+		b.syncUserPlayers(syncCtx, ctx)
+	}()
+}
+
+// syncUserPlayers синхронизирует аккаунты пользователя и отвечает итогом в чат
+func (b *Bot) syncUserPlayers(syncCtx context.Context, ctx *commandContext) {
+	result, err := services.SyncUserPlayers(syncCtx, services.ValorantClient, ctx.user)
+	var apiErr *services.ValorantAPIError
+	if errors.Is(err, services.ErrPlayerNotLinked) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &apiErr) {
+		b.replyError(ctx, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to sync Valorant players of user %d: %v", ctx.user.ID, err)
+		b.reply(ctx, tr(ctx.lang, "sync_failed"))
+		return
+	}
+
+	text := tr(ctx.lang, "sync_done", result.NewMatches, result.SkippedMatches, result.FailedMatches)
+	if result.FailedAccounts > 0 {
+		text += "\n" + tr(ctx.lang, "sync_partial", result.FailedAccounts)
+	}
+	b.reply(ctx, text)
+}
+
+func (b *Bot) cmdStats(ctx *commandContext) {
+	if strings.EqualFold(ctx.args, "team") {
+		b.teamStats(ctx)
+		return
+	}
+
+	players, err := services.GetUserPlayers(ctx.user.ID)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+	if len(players) == 0 {
+		b.replyError(ctx, services.ErrPlayerNotLinked)
+		return
+	}
+
+	lines := make([]string, 0, len(players))
+	for _, player := range players {
+		stats := player.Stats
+		if stats == nil {
+			lines = append(lines, tr(ctx.lang, "no_stats", player.GameName, player.Tag))
+			continue
+		}
+		lines = append(lines, tr(ctx.lang, "player_stats",
+			player.GameName, player.Tag, player.Rank, player.RankRating,
+			stats.TotalMatches, stats.WinRate,
+			stats.AverageKills, stats.AverageDeaths, stats.AverageAssists, stats.HeadshotRate))
+	}
+
+	b.reply(ctx, strings.Join(lines, "\n\n"))
+}
+
+func (b *Bot) teamStats(ctx *commandContext) {
+	team, ok := b.currentTeam(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := services.GetTeamStats(team.ID, services.StatsFilter{})
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	totals := stats.Totals
+	b.reply(ctx, tr(ctx.lang, "team_stats", team.Name, stats.TeamMatches, stats.TeamWinRate,
+		totals.AverageKills, totals.AverageDeaths, totals.AverageAssists, totals.HeadshotRate))
+}
+
+// currentTeam возвращает текущую команду пользователя (см. services.CurrentTeamID).
+// Если пользователь не состоит в командах, отвечает ошибкой и возвращает ok == false.
+func (b *Bot) currentTeam(ctx *commandContext) (*models.Team, bool) {
+	teamID, err := services.CurrentTeamID(ctx.user)
+	if err != nil {
+		b.replyError(ctx, err)
+		return nil, false
+	}
+
+	team, err := services.GetTeam(teamID)
+	if err != nil {
+		b.replyError(ctx, err)
+		return nil, false
+	}
+	return team, true
+}
+
+// requireTeamPermission проверяет, что пользователь состоит в команде и имеет право.
+// Возвращает ID команды; при отказе отвечает пользователю и возвращает ok == false.
+func (b *Bot) requireTeamPermission(ctx *commandContext, permission string) (uint, bool) {
+	teamID, err := services.RequireTeamPermission(ctx.user, permission)
+	if err != nil {
+		b.replyError(ctx, err)
+		return 0, false
+	}
+	return teamID, true
+}
+
+// reply отправляет ответ в чат, из которого пришла команда
+func (b *Bot) reply(ctx *commandContext, text string) {
+	msg := tgbotapi.NewMessage(ctx.message.Chat.ID, text)
+	if _, err := b.API.Send(msg); err != nil {
+		log.Printf("Failed to send message to chat %d: %v", ctx.message.Chat.ID, err)
+	}
+}
+
+// replyWithKeyboard отправляет ответ с inline-клавиатурой
+func (b *Bot) replyWithKeyboard(ctx *commandContext, text string, markup tgbotapi.InlineKeyboardMarkup) {
+	msg := tgbotapi.NewMessage(ctx.message.Chat.ID, text)
+	msg.ReplyMarkup = markup
+	if _, err := b.API.Send(msg); err != nil {
+		log.Printf("Failed to send message to chat %d: %v", ctx.message.Chat.ID, err)
+	}
+}
+
+// replyError отвечает локализованным текстом ошибки сервисного слоя
+func (b *Bot) replyError(ctx *commandContext, err error) {
+	b.reply(ctx, errorText(ctx.lang, err))
+}
+
+// errorText возвращает локализованный текст ошибки сервисного слоя
+func errorText(lang string, err error) string {
+	var permissionErr *services.PermissionError
+	if errors.As(err, &permissionErr) {
+		return tr(lang, "err_no_permission", permissionErr.Permission)
+	}
+	var escalationErr *services.EscalationError
+	if errors.As(err, &escalationErr) {
+		return tr(lang, "err_permission_escalation", escalationErr.Permission)
+	}
+	return tr(lang, errorKey(err))
+}
+
+// errorKey сопоставляет ошибку сервисного слоя ключу перевода
+func errorKey(err error) string {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return "err_user_not_found"
+	case errors.Is(err, services.ErrTeamNotFound):
+		return "err_team_not_found"
+	case errors.Is(err, services.ErrRoleNotFound):
+		return "err_role_not_found"
+	case errors.Is(err, services.ErrUserNotInTeam):
+		return "err_user_not_in_team"
+	case errors.Is(err, services.ErrAlreadyInTeam):
+		return "err_already_in_team"
+	case errors.Is(err, services.ErrNotInTeam):
+		return "err_not_in_team"
+	case errors.Is(err, services.ErrPlayerNotLinked):
+		return "err_player_not_linked"
+	case errors.Is(err, services.ErrInvitationNotFound):
+		return "err_invitation_not_found"
+	case errors.Is(err, services.ErrInvitationExpired):
+		return "err_invitation_expired"
+	case errors.Is(err, services.ErrInvitationRevoked):
+		return "err_invitation_revoked"
+	case errors.Is(err, services.ErrInvitationUsedUp):
+		return "err_invitation_used_up"
+	case errors.Is(err, services.ErrInvitationNotForUser):
+		return "err_invitation_not_for_user"
+	case errors.Is(err, services.ErrJoinRequestNotFound):
+		return "err_join_request_not_found"
+	case errors.Is(err, services.ErrJoinRequestExists):
+		return "err_join_request_exists"
+	case errors.Is(err, services.ErrJoinRequestNotPending):
+		return "err_join_request_not_pending"
+	case errors.Is(err, services.ErrRoleHierarchy):
+		return "err_role_hierarchy"
+	case errors.Is(err, services.ErrNotTeamOwner):
+		return "err_not_team_owner"
+	case errors.Is(err, services.ErrAlreadyOwner):
+		return "err_already_owner"
+	case errors.Is(err, services.ErrOwnerCannotLeave):
+		return "err_owner_cannot_leave"
+	case errors.Is(err, services.ErrProtectedRole):
+		return "err_protected_role"
+	case errors.Is(err, services.ErrTeamFull):
+		return "err_team_full"
+	case errors.Is(err, services.ErrStartersFull):
+		return "err_starters_full"
+	case errors.Is(err, services.ErrTooManyMainAgents):
+		return "err_too_many_agents"
+	case errors.Is(err, services.ErrRoleNameTaken):
+		return "err_role_name_taken"
+	case errors.Is(err, services.ErrRoleTemplateNotFound):
+		return "err_role_template_not_found"
+	case errors.Is(err, services.ErrInvalidStatus):
+		return "err_invalid_status"
+	case errors.Is(err, services.ErrInvalidInGameRole):
+		return "err_invalid_in_game_role"
+	case errors.Is(err, services.ErrRosterLimitTooLow):
+		return "err_roster_limit_too_low"
+	case errors.Is(err, services.ErrInvalidRegion):
+		return "err_invalid_region"
+	case errors.Is(err, services.ErrInvalidCursor):
+		return "err_invalid_cursor"
+	case errors.Is(err, services.ErrSyncInProgress):
+		return "err_sync_in_progress"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "err_valorant_timeout"
+	case errors.Is(err, services.ErrValorantNotFound):
+		return "err_valorant_not_found"
+	case errors.Is(err, services.ErrValorantRateLimited):
+		return "err_valorant_rate_limited"
+	case errors.Is(err, services.ErrValorantUnauthorized),
+		errors.Is(err, services.ErrValorantUpstream):
+		log.Printf("Valorant API request failed: %v", err)
+		return "err_valorant_unavailable"
+	default:
+		log.Printf("Bot command failed: %v", err)
+		return "err_internal"
+	}
+}
+
+// findMember находит участника команды по @username или ID пользователя
+func findMember(teamID uint, ref string) (*models.User, error) {
+	if userID, err := strconv.ParseUint(ref, 10, 32); err == nil {
+		return services.FindTeamMember(teamID, uint(userID))
+	}
+	return services.FindTeamMemberByUsername(teamID, strings.TrimPrefix(ref, "@"))
+}
+
+// displayName имя пользователя для сообщений бота
+func displayName(user *models.User) string {
+	name := strings.TrimSpace(fmt.Sprintf("%s %s", user.FirstName, user.LastName))
+	if user.Username != "" {
+		return fmt.Sprintf("%s (@%s)", name, user.Username)
+	}
+	return name
+}
@@ -0,0 +1,278 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dialogTimeout время ожидания ответа на шаге диалога
+const dialogTimeout = 10 * time.Minute
+
+// Названия диалогов
+const (
+	dialogLinkValorant = "link_valorant"
+	dialogCreateTeam   = "create_team"
+)
+
+// dialogStep шаг диалога: поле, вопрос и проверка ответа.
+// validate возвращает нормализованное значение или ключ перевода ошибки.
+type dialogStep struct {
+	field    string
+	prompt   func(lang string) string
+	validate func(input string) (value string, errKey string)
+}
+
+// dialog описание многошагового диалога
+type dialog struct {
+	steps  []dialogStep
+	finish func(b *Bot, ctx *commandContext, values map[string]string)
+}
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}]{3,5}$`)
+
+// dialogs доступные диалоги бота
+var dialogs = map[string]dialog{
+	dialogLinkValorant: {
+		steps: []dialogStep{
+			{"game_name", prompt("dlg_game_name"), validateGameName},
+			{"tag", prompt("dlg_tag"), validateTag},
+			{"region", func(lang string) string {
+				return tr(lang, "dlg_region", strings.Join(models.ValorantRegions, ", "))
+			}, validateRegion},
+		},
+		finish: (*Bot).finishLinkValorant,
+	},
+	dialogCreateTeam: {
+		steps: []dialogStep{
+			{"name", prompt("dlg_team_name"), validateTeamName},
+			{"description", prompt("dlg_team_description"), validateTeamDescription},
+		},
+		finish: (*Bot).finishCreateTeam,
+	},
+}
+
+// startDialog начинает диалог в чате, заменяя незавершенный
+func (b *Bot) startDialog(ctx *commandContext, name string) {
+	state := models.DialogState{
+		ChatID:         ctx.message.Chat.ID,
+		TelegramUserID: ctx.message.From.ID,
+		Dialog:         name,
+		Step:           dialogs[name].steps[0].field,
+		Data:           "{}",
+		ExpiresAt:      time.Now().Add(dialogTimeout),
+	}
+	if err := saveDialogState(&state); err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, dialogs[name].steps[0].prompt(ctx.lang)+"\n\n"+tr(ctx.lang, "dlg_cancel_hint"))
+}
+
+// handleDialogInput передает сообщение активному диалогу чата.
+// Возвращает false, если в чате нет диалога этого пользователя.
+func (b *Bot) handleDialogInput(message *tgbotapi.Message) bool {
+	state, err := loadDialogState(message.Chat.ID)
+	if err != nil {
+		log.Printf("Failed to load dialog state for chat %d: %v", message.Chat.ID, err)
+		return false
+	}
+	if state == nil || state.TelegramUserID != message.From.ID {
+		return false
+	}
+
+	ctx := &commandContext{message: message, args: strings.TrimSpace(message.Text), lang: languageOf(message.From)}
+
+	if time.Now().After(state.ExpiresAt) {
+		deleteDialogState(state.ChatID)
+		b.reply(ctx, tr(ctx.lang, "dlg_expired"))
+		return true
+	}
+
+	current, ok := dialogs[state.Dialog]
+	step := stepIndex(current, state.Step)
+	if !ok || step < 0 {
+		log.Printf("Unknown dialog state %s/%s in chat %d", state.Dialog, state.Step, state.ChatID)
+		deleteDialogState(state.ChatID)
+		return false
+	}
+
+	value, errKey := current.steps[step].validate(ctx.args)
+	if errKey != "" {
+		b.reply(ctx, tr(ctx.lang, errKey)+"\n"+current.steps[step].prompt(ctx.lang))
+		return true
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(state.Data), &values); err != nil {
+		log.Printf("Corrupted dialog data in chat %d: %v", state.ChatID, err)
+	}
+	values[current.steps[step].field] = value
+
+	// Последний шаг: диалог завершен
+	if step+1 == len(current.steps) {
+		deleteDialogState(state.ChatID)
+
+		user, err := services.FindUserByTelegramID(message.From.ID)
+		if err != nil {
+			b.replyError(ctx, err)
+			return true
+		}
+		user.Origin = messageOrigin(message)
+		ctx.user = user
+		current.finish(b, ctx, values)
+		return true
+	}
+
+	data, _ := json.Marshal(values)
+	state.Data = string(data)
+	state.Step = current.steps[step+1].field
+	state.ExpiresAt = time.Now().Add(dialogTimeout)
+	if err := saveDialogState(state); err != nil {
+		b.replyError(ctx, err)
+		return true
+	}
+
+	b.reply(ctx, current.steps[step+1].prompt(ctx.lang))
+	return true
+}
+
+func (b *Bot) cmdCancel(ctx *commandContext) {
+	state, err := loadDialogState(ctx.message.Chat.ID)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+	if state == nil || state.TelegramUserID != ctx.message.From.ID {
+		b.reply(ctx, tr(ctx.lang, "dlg_nothing_to_cancel"))
+		return
+	}
+
+	deleteDialogState(state.ChatID)
+	b.reply(ctx, tr(ctx.lang, "cancelled"))
+}
+
+func (b *Bot) finishLinkValorant(ctx *commandContext, values map[string]string) {
+	player, err := services.LinkValorantPlayer(ctx.user, values["game_name"], values["tag"], values["region"])
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "player_linked", player.GameName, player.Tag, player.Region))
+}
+
+func (b *Bot) finishCreateTeam(ctx *commandContext, values map[string]string) {
+	team, err := services.CreateTeam(ctx.user, values["name"], values["description"])
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "team_created", team.Name, team.ID))
+}
+
+// prompt возвращает функцию вопроса из ключа перевода
+func prompt(key string) func(lang string) string {
+	return func(lang string) string {
+		return tr(lang, key)
+	}
+}
+
+func stepIndex(d dialog, field string) int {
+	for i, step := range d.steps {
+		if step.field == field {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateGameName(input string) (string, string) {
+	length := utf8.RuneCountInString(input)
+	if length < 3 || length > 16 || strings.Contains(input, "#") {
+		return "", "dlg_invalid_game_name"
+	}
+	return input, ""
+}
+
+func validateTag(input string) (string, string) {
+	tag := strings.TrimPrefix(input, "#")
+	if !tagPattern.MatchString(tag) {
+		return "", "dlg_invalid_tag"
+	}
+	return tag, ""
+}
+
+func validateRegion(input string) (string, string) {
+	region := strings.ToLower(input)
+	if !models.IsValorantRegion(region) {
+		return "", "dlg_invalid_region"
+	}
+	return region, ""
+}
+
+func validateTeamName(input string) (string, string) {
+	length := utf8.RuneCountInString(input)
+	if length < 3 || length > 32 {
+		return "", "dlg_invalid_team_name"
+	}
+	return input, ""
+}
+
+func validateTeamDescription(input string) (string, string) {
+	if input == "-" {
+		return "", ""
+	}
+	if utf8.RuneCountInString(input) > 200 {
+		return "", "dlg_invalid_team_description"
+	}
+	return input, ""
+}
+
+// loadDialogState загружает диалог чата; возвращает nil, если диалога нет
+func loadDialogState(chatID int64) (*models.DialogState, error) {
+	var state models.DialogState
+	result := database.DB.Where("chat_id = ?", chatID).First(&state)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &state, nil
+}
+
+// saveDialogState сохраняет диалог; в чате может быть только один диалог
+func saveDialogState(state *models.DialogState) error {
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"telegram_user_id", "dialog", "step", "data", "expires_at", "updated_at"}),
+	}).Create(state).Error
+}
+
+func deleteDialogState(chatID int64) {
+	if err := database.DB.Where("chat_id = ?", chatID).Delete(&models.DialogState{}).Error; err != nil {
+		log.Printf("Failed to delete dialog state for chat %d: %v", chatID, err)
+	}
+}
+
+// purgeExpiredDialogs удаляет истекшие диалоги, оставшиеся после перезапуска
+func purgeExpiredDialogs() {
+	result := database.DB.Where("expires_at < ?", time.Now()).Delete(&models.DialogState{})
+	if result.Error != nil {
+		log.Printf("Failed to purge expired dialogs: %v", result.Error)
+	}
+}
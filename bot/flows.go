@@ -0,0 +1,356 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"valorant-app/models"
+	"valorant-app/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// teamsPageSize количество команд на одной странице списка
+const teamsPageSize = 5
+
+// teamsBrowser состояние списка команд в боте. Все поля, кроме Search, кодируются в callback data,
+// поэтому результаты поиска по названию показываются одной страницей.
+type teamsBrowser struct {
+	Search     string
+	Region     uint // Индекс в models.ValorantRegions + 1; 0 — любой регион
+	Recruiting bool // Только команды, набирающие игроков
+	After      uint // ID последней показанной команды; 0 — первая страница
+}
+
+// callback кодирует состояние списка для кнопки навигации
+func (s teamsBrowser) callback() callbackData {
+	var recruiting uint
+	if s.Recruiting {
+		recruiting = 1
+	}
+	return newCallback(actionTeamsPage, s.Region, recruiting, s.After)
+}
+
+// teamsPage строит страницу списка команд с кнопками вступления, фильтра набора и навигации
+func teamsPage(lang string, state teamsBrowser) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	filter := services.TeamFilter{Search: state.Search, Limit: teamsPageSize}
+	if state.Region > 0 && int(state.Region) <= len(models.ValorantRegions) {
+		filter.Region = models.ValorantRegions[state.Region-1]
+	}
+	if state.Recruiting {
+		filter.Recruiting = &state.Recruiting
+	}
+	if state.After != 0 {
+		filter.Cursor = services.TeamsAfter(state.After)
+	}
+
+	teams, next, err := services.ListTeams(filter)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, team := range teams {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button(teamLabel(&team), newCallback(actionJoinTeam, team.ID))))
+	}
+
+	recruitingToggle := state
+	recruitingToggle.Recruiting, recruitingToggle.After = !state.Recruiting, 0
+	recruitingLabel := tr(lang, "btn_recruiting_only")
+	if state.Recruiting {
+		recruitingLabel = tr(lang, "btn_all_teams")
+	}
+	navigation := []tgbotapi.InlineKeyboardButton{button(recruitingLabel, recruitingToggle.callback())}
+	if state.After != 0 {
+		first := state
+		first.After = 0
+		navigation = append(navigation, button("⏮", first.callback()))
+	}
+	if next != "" && state.Search == "" {
+		following := state
+		following.After = teams[len(teams)-1].ID
+		navigation = append(navigation, button("▶", following.callback()))
+	}
+	rows = append(rows, navigation, cancelRow(lang))
+
+	text := tr(lang, "teams_list")
+	switch {
+	case len(teams) == 0:
+		text = tr(lang, "no_teams")
+	case state.Search != "" && next != "":
+		text = tr(lang, "teams_search_more", state.Search)
+	case state.Search != "":
+		text = tr(lang, "teams_search", state.Search)
+	}
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// teamLabel подпись команды в списке: число участников, регион, средний ранг и открытый набор
+func teamLabel(team *services.TeamListItem) string {
+	parts := []string{team.Name, fmt.Sprint(team.MemberCount)}
+	if team.Region != "" {
+		parts = append(parts, strings.ToUpper(team.Region))
+	}
+	if team.AverageRankTier != "" {
+		parts = append(parts, team.AverageRankTier)
+	}
+	label := strings.Join(parts, " · ")
+	if team.Recruiting {
+		label = "🔓 " + label
+	}
+	return label
+}
+
+// leaveConfirmKeyboard кнопки подтверждения выхода из команды
+func leaveConfirmKeyboard(lang string, teamID uint) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		button(tr(lang, "btn_leave"), newCallback(actionLeaveConfirm, teamID)),
+		button(tr(lang, "btn_cancel"), newCallback(actionCancel)),
+	))
+}
+
+// transferConfirmKeyboard кнопки подтверждения передачи команды участнику
+func transferConfirmKeyboard(lang string, teamID, userID uint) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		button(tr(lang, "btn_transfer"), newCallback(actionTransferOwner, teamID, userID)),
+		button(tr(lang, "btn_cancel"), newCallback(actionCancel)),
+	))
+}
+
+// disbandConfirmKeyboard кнопки подтверждения роспуска команды
+func disbandConfirmKeyboard(lang string, teamID uint) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		button(tr(lang, "btn_disband"), newCallback(actionDisband, teamID)),
+		button(tr(lang, "btn_cancel"), newCallback(actionCancel)),
+	))
+}
+
+// switchTeamKeyboard кнопки выбора текущей команды среди остальных команд пользователя
+func switchTeamKeyboard(memberships []models.TeamMembership, currentID uint) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, membership := range memberships {
+		if membership.TeamID == currentID || membership.Team == nil {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button(membership.Team.Name, newCallback(actionSwitchTeam, membership.TeamID))))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// assignMembersKeyboard список участников команды для назначения роли
+func assignMembersKeyboard(lang string, teamID uint) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	team, err := services.GetTeam(teamID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := range team.Members {
+		member := &team.Members[i]
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button(displayName(member), newCallback(actionAssignMember, teamID, member.ID))))
+	}
+	rows = append(rows, cancelRow(lang))
+
+	return tr(lang, "assign_pick_member"), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// assignRolesKeyboard список ролей команды для выбранного участника
+func assignRolesKeyboard(lang string, teamID uint, member *models.User) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	roles, err := services.GetTeamRoles(teamID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, role := range roles {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button(role.Name, newCallback(actionAssignRole, teamID, member.ID, role.ID))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		button(tr(lang, "btn_back"), newCallback(actionAssignMembers, teamID)),
+		button(tr(lang, "btn_cancel"), newCallback(actionCancel)),
+	))
+
+	return tr(lang, "assign_pick_role", displayName(member)), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+func cancelRow(lang string) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(button(tr(lang, "btn_cancel"), newCallback(actionCancel)))
+}
+
+func (b *Bot) cbCancel(ctx *callbackContext) {
+	b.edit(ctx, tr(ctx.lang, "cancelled"), nil)
+}
+
+func (b *Bot) cbTeamsPage(ctx *callbackContext) {
+	state := teamsBrowser{Region: ctx.data.Arg(0), Recruiting: ctx.data.Arg(1) == 1, After: ctx.data.Arg(2)}
+	text, markup, err := teamsPage(ctx.lang, state)
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+	b.edit(ctx, text, &markup)
+}
+
+func (b *Bot) cbJoinTeam(ctx *callbackContext) {
+	request, err := services.CreateJoinRequest(ctx.user, ctx.data.Arg(0), "")
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "join_request_sent", request.Team.Name)
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		button(tr(ctx.lang, "btn_withdraw"), newCallback(actionJoinWithdraw, request.ID)),
+	))
+	b.answer(ctx, "")
+	b.edit(ctx, text, &markup)
+}
+
+func (b *Bot) cbJoinWithdraw(ctx *callbackContext) {
+	if _, err := services.WithdrawJoinRequest(ctx.user, ctx.data.Arg(0)); err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "join_request_withdrawn")
+	b.answer(ctx, text)
+	b.edit(ctx, text, nil)
+}
+
+func (b *Bot) cbJoinApprove(ctx *callbackContext) {
+	request, err := services.ApproveJoinRequest(ctx.user, ctx.data.Arg(0))
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "join_request_approved", displayName(request.User))
+	b.answer(ctx, text)
+	b.edit(ctx, text, nil)
+}
+
+func (b *Bot) cbJoinReject(ctx *callbackContext) {
+	request, err := services.RejectJoinRequest(ctx.user, ctx.data.Arg(0))
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "join_request_rejected", displayName(request.User))
+	b.answer(ctx, text)
+	b.edit(ctx, text, nil)
+}
+
+func (b *Bot) cbLeaveConfirm(ctx *callbackContext) {
+	if err := services.LeaveTeam(ctx.user, ctx.data.Arg(0)); err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "team_left")
+	b.answer(ctx, text)
+	b.edit(ctx, text, nil)
+}
+
+// cbAssignMembers и следующие шаги назначения роли берут команду из callback data, а не из текущей
+// команды пользователя: после смены команды старая клавиатура не должна применяться к другой команде.
+func (b *Bot) cbAssignMembers(ctx *callbackContext) {
+	teamID := ctx.data.Arg(0)
+	if err := services.CheckTeamPermission(ctx.user, teamID, models.PermissionManageRoles); err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text, markup, err := assignMembersKeyboard(ctx.lang, teamID)
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+	b.edit(ctx, text, &markup)
+}
+
+func (b *Bot) cbAssignMember(ctx *callbackContext) {
+	teamID := ctx.data.Arg(0)
+	if err := services.CheckTeamPermission(ctx.user, teamID, models.PermissionManageRoles); err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	member, err := services.FindTeamMember(teamID, ctx.data.Arg(1))
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text, markup, err := assignRolesKeyboard(ctx.lang, teamID, member)
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+	b.edit(ctx, text, &markup)
+}
+
+func (b *Bot) cbAssignRole(ctx *callbackContext) {
+	teamID := ctx.data.Arg(0)
+	if err := services.CheckTeamPermission(ctx.user, teamID, models.PermissionManageRoles); err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	memberID, roleID := ctx.data.Arg(1), ctx.data.Arg(2)
+	member, err := services.FindTeamMember(teamID, memberID)
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	role, err := services.FindTeamRole(teamID, roleID)
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	if err := services.AssignRole(ctx.user, teamID, member.ID, role.ID); err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "role_granted", role.Name, displayName(member))
+	b.answer(ctx, text)
+	b.edit(ctx, text, nil)
+}
+
+func (b *Bot) cbTransferOwner(ctx *callbackContext) {
+	team, newOwner, err := services.TransferOwnership(ctx.user, ctx.data.Arg(0), ctx.data.Arg(1))
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "ownership_transferred", team.Name, displayName(newOwner))
+	b.answer(ctx, text)
+	b.edit(ctx, text, nil)
+}
+
+func (b *Bot) cbDisband(ctx *callbackContext) {
+	team, err := services.DisbandTeam(ctx.user, ctx.data.Arg(0))
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "team_disbanded", team.Name)
+	b.answer(ctx, text)
+	b.edit(ctx, text, nil)
+}
+
+func (b *Bot) cbSwitchTeam(ctx *callbackContext) {
+	team, err := services.SetCurrentTeam(ctx.user, ctx.data.Arg(0))
+	if err != nil {
+		b.answerError(ctx, err)
+		return
+	}
+
+	text := tr(ctx.lang, "team_switched", team.Name)
+	b.answer(ctx, text)
+	b.edit(ctx, text, nil)
+}
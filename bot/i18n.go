@@ -0,0 +1,378 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// defaultLanguage язык ответов, если язык пользователя не поддерживается
+const defaultLanguage = "ru"
+
+// translations тексты ответов бота по языкам
+var translations = map[string]map[string]string{
+	"ru": {
+		"welcome": "Добро пожаловать! Используйте команды для управления командами.",
+		"help": "Доступные команды:\n" +
+			"/register — зарегистрироваться\n" +
+			"/createteam [название | описание] — создать команду\n" +
+			"/join [код приглашения] — вступить по приглашению или подать заявку\n" +
+			"/teams [регион] [название] — поиск команд\n" +
+			"/invite [число использований] — создать ссылку-приглашение\n" +
+			"/leave — покинуть команду\n" +
+			"/myteam — текущая команда и переключение между командами\n" +
+			"/roles — роли команды\n" +
+			"/assign [@username|ID роль] — назначить роль\n" +
+			"/kick <@username|ID> — исключить участника\n" +
+			"/slot <@username|ID> <позиция> — позиция участника в составе\n" +
+			"/myrole <роль> [агенты] — моя игровая роль и основные агенты\n" +
+			"/transfer <@username|ID> — передать команду участнику\n" +
+			"/disband — распустить команду\n" +
+			"/audit [N] — последние записи журнала команды\n" +
+			"/link [Имя#TAG регион] — привязать аккаунт Valorant\n" +
+			"/sync — синхронизировать данные Valorant\n" +
+			"/stats [team] — моя статистика или статистика команды\n" +
+			"/cancel — отменить текущий диалог",
+		"unknown_command": "Неизвестная команда. Список команд: /help",
+		"not_registered":  "Вы не зарегистрированы. Используйте /register.",
+		"registered":      "Готово, %s! Вы зарегистрированы.",
+
+		"usage_createteam": "Использование: /createteam <название> | <описание>",
+		"usage_invite":     "Использование: /invite [число использований, 0 — без ограничения]",
+		"usage_assign":     "Использование: /assign <@username|ID> <роль>",
+		"usage_kick":       "Использование: /kick <@username|ID>",
+		"usage_slot":       "Использование: /slot <@username|ID> <позиция>\nПозиции: %s",
+		"usage_myrole":     "Использование: /myrole <роль> [агенты]\nРоли: %s\nАгентов — не больше %d.",
+		"usage_transfer":   "Использование: /transfer <@username|ID>",
+		"usage_audit":      "Использование: /audit [число записей, до %d]",
+		"usage_link":       "Использование: /link <Имя#TAG> <регион>\nРегионы: %s",
+
+		"team_created":     "Команда «%s» создана (ID %d). Вы — владелец.",
+		"team_joined":      "Вы вступили в команду «%s».",
+		"team_left":        "Вы покинули команду.",
+		"team_info":        "Команда «%s» (ID %d)\n%s\n\nУчастники (%d):\n%s",
+		"no_members":       "нет участников",
+		"team_switch_hint": "Вы состоите в нескольких командах. Выбрать другую:",
+		"team_switched":    "Текущая команда: «%s».",
+		"roster_starters":  "Основной состав (%d/%d):",
+		"roster_subs":      "Запасные:",
+		"roster_staff":     "Штаб:",
+		"slot_starter":     "основной состав",
+		"slot_sub":         "запас",
+		"slot_coach":       "тренер",
+		"slot_analyst":     "аналитик",
+		"slot_manager":     "менеджер",
+		"slot_updated":     "%s: позиция — %s.",
+		"myrole_updated":   "Игровая роль обновлена: %s.",
+
+		"roles_header": "Роли команды:\n%s",
+		"no_roles":     "В команде нет ролей.",
+		"role_line":    "• %s — %s",
+		"no_perms":     "без прав",
+		"role_granted": "Роль «%s» назначена пользователю %s.",
+
+		"member_kicked":         "%s исключен из команды.",
+		"kicked_notice":         "Вас исключили из команды «%s».",
+		"transfer_confirm":      "Передать команду «%s» пользователю %s? Вы останетесь участником.",
+		"ownership_transferred": "Команда «%s» передана пользователю %s.",
+		"ownership_given":       "Вы передали команду «%s» пользователю %s.",
+		"ownership_received":    "%[2]s передал вам команду «%[1]s». Теперь вы владелец.",
+		"disband_confirm":       "Распустить команду «%s»? Участников: %d. Все они покинут команду, приглашения будут отозваны.",
+		"team_disbanded":        "Команда «%s» распущена.",
+		"disbanded_notice":      "Команда «%s» распущена владельцем.",
+
+		"audit_header": "Журнал команды (последние %d):\n%s",
+		"audit_empty":  "Журнал команды пуст.",
+
+		"no_teams":               "Команд пока нет.",
+		"teams_list":             "Команды (🔓 — идет набор):",
+		"teams_search":           "Команды по запросу «%s»:",
+		"teams_search_more":      "Команды по запросу «%s» (показаны первые, уточните запрос):",
+		"join_request_sent":      "Заявка в команду «%s» отправлена. Мы сообщим о решении капитана.",
+		"join_request_withdrawn": "Заявка отозвана.",
+		"join_request_new":       "%s хочет вступить в команду «%s».",
+		"join_request_approved":  "Заявка %s одобрена.",
+		"join_request_rejected":  "Заявка %s отклонена.",
+		"join_request_accepted":  "Ваша заявка в команду «%s» одобрена!",
+		"join_request_declined":  "Ваша заявка в команду «%s» отклонена.",
+		"invite_created":         "Ссылка-приглашение: %s\nДействует до %s.",
+		"leave_confirm":          "Покинуть команду «%s»?",
+		"assign_pick_member":     "Выберите участника:",
+		"assign_pick_role":       "Выберите роль для %s:",
+		"cancelled":              "Отменено.",
+		"btn_leave":              "Покинуть",
+		"btn_cancel":             "Отмена",
+		"btn_recruiting_only":    "Только с набором",
+		"btn_all_teams":          "Все команды",
+		"btn_back":               "◀ Назад",
+		"btn_approve":            "Одобрить",
+		"btn_reject":             "Отклонить",
+		"btn_withdraw":           "Отозвать заявку",
+		"btn_transfer":           "Передать",
+		"btn_disband":            "Распустить",
+
+		"dlg_cancel_hint":              "Отменить: /cancel",
+		"dlg_expired":                  "Время диалога истекло. Начните заново.",
+		"dlg_nothing_to_cancel":        "Нет активного диалога.",
+		"dlg_game_name":                "Введите игровое имя Riot ID (без тега):",
+		"dlg_tag":                      "Введите тег (3–5 символов, например EUW):",
+		"dlg_region":                   "Введите регион (%s):",
+		"dlg_team_name":                "Введите название команды:",
+		"dlg_team_description":         "Введите описание команды или «-», чтобы пропустить:",
+		"dlg_invalid_game_name":        "Имя должно содержать от 3 до 16 символов и не содержать «#».",
+		"dlg_invalid_tag":              "Тег должен состоять из 3–5 букв или цифр.",
+		"dlg_invalid_region":           "Неизвестный регион.",
+		"dlg_invalid_team_name":        "Название должно содержать от 3 до 32 символов.",
+		"dlg_invalid_team_description": "Описание не должно быть длиннее 200 символов.",
+
+		"player_linked": "Аккаунт %s#%s (%s) привязан. Выполните /sync, чтобы загрузить матчи.",
+		"sync_started":  "Синхронизация запущена…",
+		"sync_done":     "Синхронизация завершена: новых матчей %d, пропущено %d, с ошибками %d.",
+		"sync_partial":  "Не удалось синхронизировать аккаунтов: %d. Попробуйте позже.",
+		"sync_failed":   "Не удалось синхронизировать данные с Valorant API. Попробуйте позже.",
+		"player_stats":  "%s#%s — %s (%d RR)\nМатчей: %d, побед: %.1f%%\nK/D/A: %.1f / %.1f / %.1f, HS: %.1f%%",
+		"no_stats":      "%s#%s — статистики пока нет, выполните /sync.",
+		"team_stats": "Статистика команды «%s»\nМатчей: %d, побед: %.1f%%\n" +
+			"K/D/A: %.1f / %.1f / %.1f, HS: %.1f%%",
+
+		"err_user_not_found":    "Пользователь не найден.",
+		"err_team_not_found":    "Команда не найдена.",
+		"err_role_not_found":    "Роль не найдена в этой команде.",
+		"err_user_not_in_team":  "Пользователь не состоит в этой команде.",
+		"err_already_in_team":   "Пользователь уже состоит в этой команде.",
+		"err_not_in_team":       "Вы не состоите в команде.",
+		"err_player_not_linked": "Аккаунт Valorant не привязан. Используйте /link.",
+		"err_no_permission":     "Недостаточно прав: %s.",
+
+		"err_invitation_not_found":     "Приглашение не найдено.",
+		"err_invitation_expired":       "Срок действия приглашения истек.",
+		"err_invitation_revoked":       "Приглашение отозвано.",
+		"err_invitation_used_up":       "Приглашение уже использовано.",
+		"err_invitation_not_for_user":  "Это приглашение предназначено другому пользователю.",
+		"err_join_request_not_found":   "Заявка не найдена.",
+		"err_join_request_exists":      "Вы уже отправили заявку в эту команду.",
+		"err_join_request_not_pending": "Заявка уже рассмотрена.",
+		"err_role_hierarchy":           "Нельзя управлять участником с такой же или более высокой ролью.",
+		"err_not_team_owner":           "Это может сделать только владелец команды.",
+		"err_already_owner":            "Пользователь уже владелец команды.",
+		"err_owner_cannot_leave":       "Владелец не может покинуть команду. Передайте ее (/transfer) или распустите (/disband).",
+		"err_protected_role":           "Роль владельца меняется только передачей команды.",
+		"err_team_full":                "В команде нет свободных мест.",
+		"err_starters_full":            "Основной состав уже заполнен.",
+		"err_too_many_agents":          "Слишком много основных агентов.",
+		"err_role_name_taken":          "Роль с таким названием уже есть в команде.",
+		"err_role_template_not_found":  "Шаблон роли не найден в этой команде.",
+		"err_invalid_status":           "Неизвестный статус участника.",
+		"err_invalid_in_game_role":     "Неизвестная игровая роль.",
+		"err_roster_limit_too_low":     "Лимит состава меньше текущего числа участников.",
+		"err_invalid_region":           "Неизвестный регион.",
+		"err_invalid_cursor":           "Список устарел. Откройте его заново.",
+		"err_sync_in_progress":         "Синхронизация этого аккаунта уже идет. Подождите немного.",
+		"err_valorant_not_found":       "Аккаунт не найден в Valorant API. Проверьте имя, тег и регион.",
+		"err_valorant_rate_limited":    "Слишком много запросов к Valorant API. Попробуйте через минуту.",
+		"err_valorant_unavailable":     "Valorant API сейчас недоступен. Попробуйте позже.",
+		"err_valorant_timeout":         "Valorant API отвечает слишком долго. Попробуйте позже.",
+		"err_permission_escalation":    "Нельзя выдать право, которого нет у вас: %s.",
+		"err_internal":                 "Что-то пошло не так. Попробуйте позже.",
+	},
+	"en": {
+		"welcome": "Welcome! Use the commands to manage your teams.",
+		"help": "Available commands:\n" +
+			"/register — sign up\n" +
+			"/createteam [name | description] — create a team\n" +
+			"/join [invite code] — join with an invitation or request to join\n" +
+			"/teams [region] [name] — browse teams\n" +
+			"/invite [max uses] — create an invitation link\n" +
+			"/leave — leave your team\n" +
+			"/myteam — current team and switching between teams\n" +
+			"/roles — team roles\n" +
+			"/assign [@username|ID role] — assign a role\n" +
+			"/kick <@username|ID> — remove a member\n" +
+			"/slot <@username|ID> <slot> — a member's roster slot\n" +
+			"/myrole <role> [agents] — your in-game role and main agents\n" +
+			"/transfer <@username|ID> — hand the team over to a member\n" +
+			"/disband — disband your team\n" +
+			"/audit [N] — latest team audit log entries\n" +
+			"/link [Name#TAG region] — link a Valorant account\n" +
+			"/sync — sync Valorant data\n" +
+			"/stats [team] — your stats or team stats\n" +
+			"/cancel — cancel the current dialog",
+		"unknown_command": "Unknown command. See /help for the list of commands.",
+		"not_registered":  "You are not registered yet. Use /register.",
+		"registered":      "Done, %s! You are registered.",
+
+		"usage_createteam": "Usage: /createteam <name> | <description>",
+		"usage_invite":     "Usage: /invite [max uses, 0 for unlimited]",
+		"usage_assign":     "Usage: /assign <@username|ID> <role>",
+		"usage_kick":       "Usage: /kick <@username|ID>",
+		"usage_slot":       "Usage: /slot <@username|ID> <slot>\nSlots: %s",
+		"usage_myrole":     "Usage: /myrole <role> [agents]\nRoles: %s\nUp to %d agents.",
+		"usage_transfer":   "Usage: /transfer <@username|ID>",
+		"usage_audit":      "Usage: /audit [number of entries, up to %d]",
+		"usage_link":       "Usage: /link <Name#TAG> <region>\nRegions: %s",
+
+		"team_created":     "Team \"%s\" created (ID %d). You are the owner.",
+		"team_joined":      "You joined team \"%s\".",
+		"team_left":        "You left the team.",
+		"team_info":        "Team \"%s\" (ID %d)\n%s\n\nMembers (%d):\n%s",
+		"no_members":       "no members",
+		"team_switch_hint": "You are on several teams. Switch to:",
+		"team_switched":    "Current team: \"%s\".",
+		"roster_starters":  "Starting lineup (%d/%d):",
+		"roster_subs":      "Substitutes:",
+		"roster_staff":     "Staff:",
+		"slot_starter":     "starter",
+		"slot_sub":         "substitute",
+		"slot_coach":       "coach",
+		"slot_analyst":     "analyst",
+		"slot_manager":     "manager",
+		"slot_updated":     "%s: slot — %s.",
+		"myrole_updated":   "In-game role updated: %s.",
+
+		"roles_header": "Team roles:\n%s",
+		"no_roles":     "The team has no roles.",
+		"role_line":    "• %s — %s",
+		"no_perms":     "no permissions",
+		"role_granted": "Role \"%s\" assigned to %s.",
+
+		"member_kicked":         "%s has been removed from the team.",
+		"kicked_notice":         "You have been removed from team \"%s\".",
+		"transfer_confirm":      "Hand team \"%s\" over to %s? You will stay in the team as a member.",
+		"ownership_transferred": "Team \"%s\" has been handed over to %s.",
+		"ownership_given":       "You handed team \"%s\" over to %s.",
+		"ownership_received":    "%[2]s handed team \"%[1]s\" over to you. You are now the owner.",
+		"disband_confirm":       "Disband team \"%s\"? It has %d members. All of them will leave the team and invitations will be revoked.",
+		"team_disbanded":        "Team \"%s\" has been disbanded.",
+		"disbanded_notice":      "Team \"%s\" has been disbanded by its owner.",
+
+		"audit_header": "Team audit log (latest %d):\n%s",
+		"audit_empty":  "The team audit log is empty.",
+
+		"no_teams":               "There are no teams yet.",
+		"teams_list":             "Teams (🔓 — recruiting):",
+		"teams_search":           "Teams matching \"%s\":",
+		"teams_search_more":      "Teams matching \"%s\" (showing the first ones, refine your search):",
+		"join_request_sent":      "Your request to join \"%s\" has been sent. We will let you know what the captain decides.",
+		"join_request_withdrawn": "Join request withdrawn.",
+		"join_request_new":       "%s wants to join team \"%s\".",
+		"join_request_approved":  "Join request from %s approved.",
+		"join_request_rejected":  "Join request from %s rejected.",
+		"join_request_accepted":  "Your request to join \"%s\" has been approved!",
+		"join_request_declined":  "Your request to join \"%s\" has been rejected.",
+		"invite_created":         "Invitation link: %s\nValid until %s.",
+		"leave_confirm":          "Leave team \"%s\"?",
+		"assign_pick_member":     "Pick a member:",
+		"assign_pick_role":       "Pick a role for %s:",
+		"cancelled":              "Cancelled.",
+		"btn_leave":              "Leave",
+		"btn_cancel":             "Cancel",
+		"btn_recruiting_only":    "Recruiting only",
+		"btn_all_teams":          "All teams",
+		"btn_back":               "◀ Back",
+		"btn_approve":            "Approve",
+		"btn_reject":             "Reject",
+		"btn_withdraw":           "Withdraw request",
+		"btn_transfer":           "Hand over",
+		"btn_disband":            "Disband",
+
+		"dlg_cancel_hint":              "Cancel: /cancel",
+		"dlg_expired":                  "The dialog has expired. Please start again.",
+		"dlg_nothing_to_cancel":        "There is no active dialog.",
+		"dlg_game_name":                "Enter your Riot ID game name (without the tag):",
+		"dlg_tag":                      "Enter your tag (3–5 characters, e.g. EUW):",
+		"dlg_region":                   "Enter your region (%s):",
+		"dlg_team_name":                "Enter the team name:",
+		"dlg_team_description":         "Enter the team description or \"-\" to skip:",
+		"dlg_invalid_game_name":        "The name must be 3 to 16 characters long and must not contain \"#\".",
+		"dlg_invalid_tag":              "The tag must be 3–5 letters or digits.",
+		"dlg_invalid_region":           "Unknown region.",
+		"dlg_invalid_team_name":        "The name must be 3 to 32 characters long.",
+		"dlg_invalid_team_description": "The description must be at most 200 characters long.",
+
+		"player_linked": "Account %s#%s (%s) linked. Run /sync to load matches.",
+		"sync_started":  "Sync started…",
+		"sync_done":     "Sync finished: %d new matches, %d skipped, %d failed.",
+		"sync_partial":  "Failed to sync %d account(s). Try again later.",
+		"sync_failed":   "Could not sync data with the Valorant API. Try again later.",
+		"player_stats":  "%s#%s — %s (%d RR)\nMatches: %d, win rate: %.1f%%\nK/D/A: %.1f / %.1f / %.1f, HS: %.1f%%",
+		"no_stats":      "%s#%s — no stats yet, run /sync.",
+		"team_stats": "Team \"%s\" stats\nMatches: %d, win rate: %.1f%%\n" +
+			"K/D/A: %.1f / %.1f / %.1f, HS: %.1f%%",
+
+		"err_user_not_found":    "User not found.",
+		"err_team_not_found":    "Team not found.",
+		"err_role_not_found":    "Role not found in this team.",
+		"err_user_not_in_team":  "The user is not a member of this team.",
+		"err_already_in_team":   "The user is already a member of this team.",
+		"err_not_in_team":       "You are not in a team.",
+		"err_player_not_linked": "No Valorant account linked. Use /link.",
+		"err_no_permission":     "Missing permission: %s.",
+
+		"err_invitation_not_found":     "Invitation not found.",
+		"err_invitation_expired":       "The invitation has expired.",
+		"err_invitation_revoked":       "The invitation has been revoked.",
+		"err_invitation_used_up":       "The invitation has already been used.",
+		"err_invitation_not_for_user":  "This invitation is meant for another user.",
+		"err_join_request_not_found":   "Join request not found.",
+		"err_join_request_exists":      "You have already asked to join this team.",
+		"err_join_request_not_pending": "The join request has already been processed.",
+		"err_role_hierarchy":           "You cannot manage a member with an equal or higher role.",
+		"err_not_team_owner":           "Only the team owner can do this.",
+		"err_already_owner":            "The user already owns the team.",
+		"err_owner_cannot_leave":       "The owner cannot leave the team. Hand it over (/transfer) or disband it (/disband).",
+		"err_protected_role":           "The owner role changes only by handing the team over.",
+		"err_team_full":                "The team has no free spots.",
+		"err_starters_full":            "The starting lineup is already full.",
+		"err_too_many_agents":          "Too many main agents.",
+		"err_role_name_taken":          "A role with this name already exists in the team.",
+		"err_role_template_not_found":  "Role template not found in this team.",
+		"err_invalid_status":           "Unknown member status.",
+		"err_invalid_in_game_role":     "Unknown in-game role.",
+		"err_roster_limit_too_low":     "The roster limit is below the current number of members.",
+		"err_invalid_region":           "Unknown region.",
+		"err_invalid_cursor":           "The list is out of date. Open it again.",
+		"err_sync_in_progress":         "This account is already syncing. Please wait a moment.",
+		"err_valorant_not_found":       "Account not found in the Valorant API. Check the name, tag and region.",
+		"err_valorant_rate_limited":    "Too many requests to the Valorant API. Try again in a minute.",
+		"err_valorant_unavailable":     "The Valorant API is unavailable right now. Try again later.",
+		"err_valorant_timeout":         "The Valorant API is taking too long to respond. Try again later.",
+		"err_permission_escalation":    "You cannot grant a permission you do not have: %s.",
+		"err_internal":                 "Something went wrong. Try again later.",
+	},
+}
+
+// tr возвращает перевод ключа key на язык lang с подстановкой аргументов
+func tr(lang, key string, args ...interface{}) string {
+	text, ok := translations[lang][key]
+	if !ok {
+		text, ok = translations[defaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// languageOf определяет язык ответов по настройкам пользователя Telegram
+func languageOf(user *tgbotapi.User) string {
+	if user == nil {
+		return defaultLanguage
+	}
+	return normalizeLanguage(user.LanguageCode)
+}
+
+// normalizeLanguage приводит код языка IETF к поддерживаемому языку ответов
+func normalizeLanguage(code string) string {
+	lang := strings.ToLower(code)
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	if _, ok := translations[lang]; ok {
+		return lang
+	}
+	return defaultLanguage
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"log"
+	"valorant-app/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// NotifyJoinRequest отправляет рецензентам заявку с кнопками одобрения и отклонения
+func (b *Bot) NotifyJoinRequest(request *models.JoinRequest, reviewers []models.User) {
+	for i := range reviewers {
+		reviewer := &reviewers[i]
+		lang := normalizeLanguage(reviewer.LanguageCode)
+
+		text := tr(lang, "join_request_new", displayName(request.User), request.Team.Name)
+		if request.Message != "" {
+			text += "\n\n" + request.Message
+		}
+		markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			button(tr(lang, "btn_approve"), newCallback(actionJoinApprove, request.ID)),
+			button(tr(lang, "btn_reject"), newCallback(actionJoinReject, request.ID)),
+		))
+		b.sendTo(reviewer, text, &markup)
+	}
+}
+
+// NotifyJoinRequestResolved сообщает кандидату о решении по заявке
+func (b *Bot) NotifyJoinRequestResolved(request *models.JoinRequest) {
+	lang := normalizeLanguage(request.User.LanguageCode)
+
+	key := "join_request_declined"
+	if request.Status == models.JoinRequestApproved {
+		key = "join_request_accepted"
+	}
+	b.sendTo(request.User, tr(lang, key, request.Team.Name), nil)
+}
+
+// sendTo отправляет личное сообщение пользователю
+func (b *Bot) sendTo(user *models.User, text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	msg := tgbotapi.NewMessage(user.TelegramID, text)
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	if _, err := b.API.Send(msg); err != nil {
+		log.Printf("Failed to notify user %d: %v", user.ID, err)
+	}
+}
+
+// NotifyMemberKicked сообщает участнику об исключении из команды
+func (b *Bot) NotifyMemberKicked(team *models.Team, member *models.User) {
+	b.sendTo(member, tr(normalizeLanguage(member.LanguageCode), "kicked_notice", team.Name), nil)
+}
+
+// NotifyOwnershipTransferred сообщает прежнему и новому владельцу о передаче команды
+func (b *Bot) NotifyOwnershipTransferred(team *models.Team, previous, next *models.User) {
+	b.sendTo(previous, tr(normalizeLanguage(previous.LanguageCode), "ownership_given", team.Name, displayName(next)), nil)
+	b.sendTo(next, tr(normalizeLanguage(next.LanguageCode), "ownership_received", team.Name, displayName(previous)), nil)
+}
+
+// NotifyTeamDisbanded сообщает бывшим участникам о роспуске команды
+func (b *Bot) NotifyTeamDisbanded(team *models.Team, members []models.User) {
+	for i := range members {
+		member := &members[i]
+		if member.ID == team.CreatedBy {
+			continue
+		}
+		b.sendTo(member, tr(normalizeLanguage(member.LanguageCode), "disbanded_notice", team.Name), nil)
+	}
+}
@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"valorant-app/models"
+	"valorant-app/services"
+)
+
+// cmdSlot меняет позицию участника в составе текущей команды
+func (b *Bot) cmdSlot(ctx *commandContext) {
+	args := strings.Fields(ctx.args)
+	if len(args) != 2 || !models.IsRosterSlot(args[1]) {
+		b.reply(ctx, tr(ctx.lang, "usage_slot", strings.Join(models.RosterSlots, ", ")))
+		return
+	}
+
+	teamID, ok := b.requireTeamPermission(ctx, models.PermissionManageTeam)
+	if !ok {
+		return
+	}
+
+	target, err := findMember(teamID, args[0])
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	slot := args[1]
+	if _, err := services.UpdateRosterEntry(ctx.user, teamID, target.ID, services.RosterUpdate{RosterSlot: &slot}); err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "slot_updated", displayName(target), tr(ctx.lang, "slot_"+slot)))
+}
+
+// cmdMyRole задает свою игровую роль и основных агентов в текущей команде
+func (b *Bot) cmdMyRole(ctx *commandContext) {
+	args := strings.Fields(ctx.args)
+	if len(args) == 0 || !models.IsInGameRole(strings.ToLower(args[0])) {
+		b.reply(ctx, tr(ctx.lang, "usage_myrole", strings.Join(models.InGameRoles, ", "), models.MaxMainAgents))
+		return
+	}
+
+	teamID, err := services.CurrentTeamID(ctx.user)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	role := strings.ToLower(args[0])
+	update := services.RosterUpdate{InGameRole: &role, MainAgents: args[1:]}
+	membership, err := services.UpdateRosterEntry(ctx.user, teamID, ctx.user.ID, update)
+	if err != nil {
+		b.replyError(ctx, err)
+		return
+	}
+
+	b.reply(ctx, tr(ctx.lang, "myrole_updated", rosterPlayerInfo(membership)))
+}
+
+// rosterText описание состава по позициям для /myteam
+func rosterText(lang string, roster *services.Roster) string {
+	var sections []string
+	if len(roster.Starters) > 0 {
+		sections = append(sections, tr(lang, "roster_starters", len(roster.Starters), roster.MaxStarters)+"\n"+
+			rosterLines(roster.Starters, rosterPlayerInfo))
+	}
+	if len(roster.Substitutes) > 0 {
+		sections = append(sections, tr(lang, "roster_subs")+"\n"+rosterLines(roster.Substitutes, rosterPlayerInfo))
+	}
+	if len(roster.Staff) > 0 {
+		staffInfo := func(membership *models.TeamMembership) string {
+			if membership.RosterSlot == "" {
+				return ""
+			}
+			return tr(lang, "slot_"+membership.RosterSlot)
+		}
+		sections = append(sections, tr(lang, "roster_staff")+"\n"+rosterLines(roster.Staff, staffInfo))
+	}
+	if len(sections) == 0 {
+		return tr(lang, "no_members")
+	}
+	return strings.Join(sections, "\n")
+}
+
+// rosterLines строки участников раздела состава с дополнительной информацией
+func rosterLines(memberships []models.TeamMembership, info func(*models.TeamMembership) string) string {
+	lines := make([]string, 0, len(memberships))
+	for i := range memberships {
+		membership := &memberships[i]
+		line := "• "
+		if membership.User != nil {
+			line += displayName(membership.User)
+		} else {
+			line += fmt.Sprintf("ID %d", membership.UserID)
+		}
+		if extra := info(membership); extra != "" {
+			line += " — " + extra
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rosterPlayerInfo игровая роль и основные агенты игрока
+func rosterPlayerInfo(membership *models.TeamMembership) string {
+	info := membership.InGameRole
+	if len(membership.MainAgents) > 0 {
+		agents := strings.Join(membership.MainAgents, ", ")
+		if info == "" {
+			return agents
+		}
+		info += ": " + agents
+	}
+	return info
+}
@@ -4,22 +4,32 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	TelegramBotToken string
-	WebhookURL       string
-	Port             string
-	NgrokURL         string
-	DBHost           string
-	DBPort           string
-	DBUser           string
-	DBPassword       string
-	DBName           string
-	DBSSLMode        string
-	ValorantAPIKey   string
+	TelegramBotToken  string
+	WebhookURL        string
+	Port              string
+	NgrokURL          string
+	DBHost            string
+	DBPort            string
+	DBUser            string
+	DBPassword        string
+	DBName            string
+	DBSSLMode         string
+	ValorantAPIKey    string
+	ValorantProvider  string  // riot, henrik или fake
+	ValorantAPIURL    string  // Адрес API вместо адреса поставщика по умолчанию
+	ValorantFakeAddr  string  // Адрес фейкового API для VALORANT_PROVIDER=fake
+	ValorantRateLimit string  // Лимиты запросов "запросы:секунды" через запятую; пусто — лимиты ключа разработчика
+	ValorantCacheSize int     // Количество ответов Valorant API в кэше в памяти; 0 — размер по умолчанию
+	SyncInterval      int     // Как часто синхронизировать каждый аккаунт в фоне; 0 — отключено
+	SyncWorkers       int     // Одновременно синхронизируемых аккаунтов
+	AdminTelegramIDs  []int64 // Telegram ID администраторов приложения
+	AuthMaxAge        int
 }
 
 func LoadConfig() *Config {
@@ -29,17 +39,26 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
-		WebhookURL:       getEnv("WEBHOOK_URL", ""),
-		Port:             getEnv("PORT", "8080"),
-		NgrokURL:         getEnv("NGROK_URL", ""),
-		DBHost:           getEnv("DB_HOST", "localhost"),
-		DBPort:           getEnv("DB_PORT", "5432"),
-		DBUser:           getEnv("DB_USER", "valorant_user"),
-		DBPassword:       getEnv("DB_PASSWORD", ""),
-		DBName:           getEnv("DB_NAME", "valorant_db"),
-		DBSSLMode:        getEnv("DB_SSLMODE", "disable"),
-		ValorantAPIKey:   getEnv("VALORANT_API_KEY", ""),
+		TelegramBotToken:  getEnv("TELEGRAM_BOT_TOKEN", ""),
+		WebhookURL:        getEnv("WEBHOOK_URL", ""),
+		Port:              getEnv("PORT", "8080"),
+		NgrokURL:          getEnv("NGROK_URL", ""),
+		DBHost:            getEnv("DB_HOST", "localhost"),
+		DBPort:            getEnv("DB_PORT", "5432"),
+		DBUser:            getEnv("DB_USER", "valorant_user"),
+		DBPassword:        getEnv("DB_PASSWORD", ""),
+		DBName:            getEnv("DB_NAME", "valorant_db"),
+		DBSSLMode:         getEnv("DB_SSLMODE", "disable"),
+		ValorantAPIKey:    getEnv("VALORANT_API_KEY", ""),
+		ValorantProvider:  getEnv("VALORANT_PROVIDER", "riot"),
+		ValorantAPIURL:    getEnv("VALORANT_API_URL", ""),
+		ValorantFakeAddr:  getEnv("VALORANT_FAKE_ADDR", ""),
+		ValorantRateLimit: getEnv("VALORANT_RATE_LIMIT", ""),
+		ValorantCacheSize: getEnvAsInt("VALORANT_CACHE_SIZE", 0),
+		SyncInterval:      getEnvAsInt("VALORANT_SYNC_INTERVAL", 60),
+		SyncWorkers:       getEnvAsInt("VALORANT_SYNC_WORKERS", 2),
+		AdminTelegramIDs:  getEnvAsInt64List("ADMIN_TELEGRAM_IDS"),
+		AuthMaxAge:        getEnvAsInt("AUTH_MAX_AGE", 86400),
 	}
 }
 
@@ -50,6 +69,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvAsInt64List разбирает список чисел через запятую; некорректные значения пропускаются
+func getEnvAsInt64List(key string) []int64 {
+	var values []int64
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if intValue, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			values = append(values, intValue)
+		}
+	}
+	return values
+}
+
 func getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -24,23 +24,52 @@ func InitDB(cfg *config.Config) {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	// Auto migrate the schema
-	err = DB.AutoMigrate(
+	if err := Migrate(DB); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	// Триграммный индекс ускоряет поиск команд по названию; без pg_trgm поиск работает без индекса
+	if err := DB.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("pg_trgm is unavailable, team search will not use an index: %v", err)
+	} else if err := DB.Exec("CREATE INDEX IF NOT EXISTS idx_teams_name_trgm ON teams USING gin (name gin_trgm_ops)").Error; err != nil {
+		log.Printf("Failed to create team search index: %v", err)
+	}
+
+	// Seed the database with initial data
+	seeders.SeedAll(DB)
+
+	log.Println("Database connected and migrated successfully")
+}
+
+// Migrate настраивает таблицу участников команд и создает или обновляет схему всех моделей
+func Migrate(db *gorm.DB) error {
+	// Участники команд хранятся в team_memberships со статусом и позицией в составе
+	if err := db.SetupJoinTable(&models.Team{}, "Members", &models.TeamMembership{}); err != nil {
+		return fmt.Errorf("setup team memberships: %w", err)
+	}
+	if err := db.SetupJoinTable(&models.User{}, "Teams", &models.TeamMembership{}); err != nil {
+		return fmt.Errorf("setup team memberships: %w", err)
+	}
+
+	return db.AutoMigrate(
 		&models.User{},
 		&models.Team{},
+		&models.TeamMembership{},
+		&models.Organization{},
+		&models.OrganizationMember{},
 		&models.Role{},
 		&models.Permission{},
+		&models.RoleTemplate{},
 		&models.ValorantPlayer{},
 		&models.ValorantMatch{},
 		&models.ValorantPlayerMatch{},
+		&models.TeamMatchArchive{},
 		&models.ValorantStats{},
+		&models.ValorantMatchCache{},
+		&models.DialogState{},
+		&models.Invitation{},
+		&models.InvitationRedemption{},
+		&models.JoinRequest{},
+		&models.AuditEvent{},
 	)
-	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
-	}
-
-	// Seed the database with initial data
-	seeders.SeedAll(DB)
-
-	log.Println("Database connected and migrated successfully")
 }
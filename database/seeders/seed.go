@@ -11,101 +11,270 @@ func SeedAll(db *gorm.DB) {
 	log.Println("Starting database seeding...")
 
 	seedPermissions(db)
-	seedDefaultRoles(db)
+	seedRoleTemplates(db)
+	migrateUserTeams(db)
+	backfillRosterSlots(db)
+	migrateGlobalRoles(db)
+	syncOwnerPermissions(db)
 
 	log.Println("Database seeding completed!")
 }
 
+// seedPermissions синхронизирует каталог прав из models с таблицей permissions
 func seedPermissions(db *gorm.DB) {
-	permissions := []models.Permission{
-		{Name: models.PermissionManageTeam, Description: "Управление командой"},
-		{Name: models.PermissionManageRoles, Description: "Управление ролями"},
-		{Name: models.PermissionKickMembers, Description: "Исключение участников"},
-		{Name: models.PermissionInviteMembers, Description: "Приглашение участников"},
-		{Name: models.PermissionViewMembers, Description: "Просмотр участников"},
-		{Name: models.PermissionEditProfile, Description: "Редактирование профиля"},
-	}
+	for _, definition := range models.RegisteredPermissions() {
+		description := models.Localized(definition.Descriptions, models.DefaultPermissionLanguage)
 
-	for _, permission := range permissions {
 		var existingPermission models.Permission
-		result := db.Where("name = ?", permission.Name).First(&existingPermission)
+		result := db.Unscoped().Where("name = ?", definition.Name).First(&existingPermission)
 		if result.Error != nil {
 			// Permission doesn't exist, create it
-			db.Create(&permission)
-			log.Printf("Created permission: %s", permission.Name)
+			db.Create(&models.Permission{Name: definition.Name, Group: definition.Group, Description: description})
+			log.Printf("Created permission: %s", definition.Name)
+			continue
+		}
+
+		if existingPermission.Group != definition.Group || existingPermission.Description != description ||
+			existingPermission.DeletedAt.Valid {
+			db.Unscoped().Model(&existingPermission).Updates(map[string]interface{}{
+				"permission_group": definition.Group,
+				"description":      description,
+				"deleted_at":       nil,
+			})
 		}
 	}
 }
 
-func seedDefaultRoles(db *gorm.DB) {
-	// Создаем базовые права для каждой роли
-	ownerPermissions := []string{
-		models.PermissionManageTeam,
-		models.PermissionManageRoles,
-		models.PermissionKickMembers,
-		models.PermissionInviteMembers,
-		models.PermissionViewMembers,
-		models.PermissionEditProfile,
-	}
+// roleTemplateSeed описание шаблона роли из общего каталога
+type roleTemplateSeed struct {
+	name        string
+	title       string
+	description string
+	position    int
+	permissions []string
+}
 
-	adminPermissions := []string{
+// defaultRoleTemplates каталог ролей, которые получает каждая новая команда
+var defaultRoleTemplates = []roleTemplateSeed{
+	// Права владельца дополняются всеми правами каталога в seedRoleTemplates
+	{models.RoleOwner, "Владелец команды", "Полный доступ ко всем функциям команды", 40, nil},
+	{models.RoleAdmin, "Администратор", "Управление командой и участниками", 30, []string{
 		models.PermissionManageTeam,
 		models.PermissionKickMembers,
 		models.PermissionInviteMembers,
 		models.PermissionViewMembers,
 		models.PermissionEditProfile,
-	}
-
-	captainPermissions := []string{
+	}},
+	{models.RoleCaptain, "Капитан", "Управление участниками команды", 20, []string{
 		models.PermissionKickMembers,
 		models.PermissionInviteMembers,
 		models.PermissionViewMembers,
 		models.PermissionEditProfile,
-	}
-
-	memberPermissions := []string{
+	}},
+	{models.RoleMember, "Участник", "Базовые права участника", 10, []string{
 		models.PermissionViewMembers,
 		models.PermissionEditProfile,
+	}},
+}
+
+func seedRoleTemplates(db *gorm.DB) {
+	for _, seed := range defaultRoleTemplates {
+		if seed.name == models.RoleOwner {
+			seed.permissions = models.RegisteredPermissionNames()
+		}
+
+		var permissions []models.Permission
+		db.Where("name IN ?", seed.permissions).Find(&permissions)
+
+		var template models.RoleTemplate
+		result := db.Where("name = ? AND team_id IS NULL", seed.name).First(&template)
+		if result.Error == nil {
+			// Шаблон уже есть, обновляем права
+			db.Model(&template).Association("Permissions").Replace(permissions)
+			backfillRolePositions(db, seed)
+			continue
+		}
+
+		template = models.RoleTemplate{
+			Name:        seed.name,
+			Title:       seed.title,
+			Description: seed.description,
+			Position:    seed.position,
+			Permissions: permissions,
+		}
+		db.Create(&template)
+		log.Printf("Created role template: %s", seed.name)
+		backfillRolePositions(db, seed)
 	}
+}
 
-	// Создаем роли с правами
-	createRoleWithPermissions(db, models.RoleOwner, "Владелец команды", "Полный доступ ко всем функциям команды", ownerPermissions)
-	createRoleWithPermissions(db, models.RoleAdmin, "Администратор", "Управление командой и участниками", adminPermissions)
-	createRoleWithPermissions(db, models.RoleCaptain, "Капитан", "Управление участниками команды", captainPermissions)
-	createRoleWithPermissions(db, models.RoleMember, "Участник", "Базовые права участника", memberPermissions)
+// backfillRolePositions проставляет позицию стандартным ролям и шаблонам,
+// созданным до появления иерархии ролей
+func backfillRolePositions(db *gorm.DB, seed roleTemplateSeed) {
+	db.Model(&models.RoleTemplate{}).Where("name = ? AND position = 0", seed.name).Update("position", seed.position)
+	db.Model(&models.Role{}).Where("name = ? AND position = 0", seed.name).Update("position", seed.position)
 }
 
-func createRoleWithPermissions(db *gorm.DB, roleName, description, fullDescription string, permissionNames []string) {
-	// Проверяем, существует ли роль
-	var existingRole models.Role
-	result := db.Where("name = ?", roleName).First(&existingRole)
-	if result.Error == nil {
-		// Роль уже существует, обновляем права
-		updateRolePermissions(db, &existingRole, permissionNames)
+// migrateUserTeams переносит прежнюю единственную команду пользователя (users.team_id)
+// в team_memberships и удаляет колонку. Выполняется один раз: после удаления колонки ничего не делает.
+func migrateUserTeams(db *gorm.DB) {
+	if !db.Migrator().HasColumn("users", "team_id") {
 		return
 	}
 
-	// Создаем новую роль
-	role := models.Role{
-		Name:        roleName,
-		Description: description,
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO team_memberships (team_id, user_id, status, roster_slot, joined_at, created_at, updated_at)
+			SELECT u.team_id, u.id, ?, '', u.updated_at, NOW(), NOW() FROM users u
+			JOIN teams t ON t.id = u.team_id AND t.deleted_at IS NULL
+			WHERE u.team_id IS NOT NULL
+			ON CONFLICT DO NOTHING`, models.MembershipActive).Error
+		if err != nil {
+			return err
+		}
+
+		// Прежняя команда остается выбранной в боте
+		err = tx.Exec(`UPDATE users SET current_team_id = team_id
+			WHERE current_team_id IS NULL AND team_id IN (SELECT id FROM teams WHERE deleted_at IS NULL)`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn("users", "team_id")
+	})
+	if err != nil {
+		log.Printf("Failed to migrate user teams: %v", err)
+		return
 	}
+	log.Println("Migrated users.team_id to team memberships")
+}
 
-	// Находим права по именам
-	var permissions []models.Permission
-	db.Where("name IN ?", permissionNames).Find(&permissions)
-	role.Permissions = permissions
+// backfillRosterSlots распределяет участников без позиции в составе: первые по времени вступления
+// занимают основной состав, остальные становятся запасными
+func backfillRosterSlots(db *gorm.DB) {
+	err := db.Exec(`UPDATE team_memberships m
+		SET roster_slot = CASE WHEN r.n + r.starters <= ? THEN ? ELSE ? END
+		FROM (
+			SELECT team_id, user_id, ROW_NUMBER() OVER (PARTITION BY team_id ORDER BY joined_at, user_id) AS n,
+				(SELECT COUNT(*) FROM team_memberships s WHERE s.team_id = u.team_id AND s.roster_slot = ?) AS starters
+			FROM team_memberships u WHERE u.roster_slot = '' OR u.roster_slot IS NULL
+		) r
+		WHERE m.team_id = r.team_id AND m.user_id = r.user_id`,
+		models.MaxStarters, models.RosterStarter, models.RosterSub, models.RosterStarter).Error
+	if err != nil {
+		log.Printf("Failed to backfill roster slots: %v", err)
+	}
+}
+
+// migrateGlobalRoles переносит назначения глобальных ролей (TeamID 0, созданных прежним сидером)
+// на роли команд пользователей и удаляет глобальные роли. Командам без полного набора ролей
+// создаются недостающие роли из шаблонов.
+func migrateGlobalRoles(db *gorm.DB) {
+	var teamIDs []uint
+	db.Model(&models.Team{}).Pluck("id", &teamIDs)
+	for _, teamID := range teamIDs {
+		if err := SeedTeamRoles(db, teamID); err != nil {
+			log.Printf("Failed to seed roles for team %d: %v", teamID, err)
+		}
+	}
+
+	var globalRoles []models.Role
+	db.Unscoped().Where("team_id = 0").Find(&globalRoles)
+	for _, globalRole := range globalRoles {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Пользователь с глобальной ролью получает одноименную роль своей команды
+			err := tx.Exec(`INSERT INTO user_roles (user_id, role_id)
+				SELECT ur.user_id, r.id FROM user_roles ur
+				JOIN team_memberships m ON m.user_id = ur.user_id
+				JOIN roles r ON r.team_id = m.team_id AND r.name = ? AND r.deleted_at IS NULL
+				WHERE ur.role_id = ?
+				ON CONFLICT DO NOTHING`, globalRole.Name, globalRole.ID).Error
+			if err != nil {
+				return err
+			}
 
-	db.Create(&role)
-	log.Printf("Created role: %s", roleName)
+			if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", globalRole.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", globalRole.ID).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&globalRole).Error
+		})
+		if err != nil {
+			log.Printf("Failed to migrate global role %s: %v", globalRole.Name, err)
+			continue
+		}
+		log.Printf("Migrated global role: %s", globalRole.Name)
+	}
 }
 
-func updateRolePermissions(db *gorm.DB, role *models.Role, permissionNames []string) {
-	// Находим права по именам
+// SeedTeamRoles создает команде недостающие роли по ее шаблонам.
+// Если у команды еще нет своих шаблонов, они копируются из общего каталога.
+func SeedTeamRoles(db *gorm.DB, teamID uint) error {
+	var templates []models.RoleTemplate
+	if err := db.Preload("Permissions").Where("team_id = ?", teamID).Find(&templates).Error; err != nil {
+		return err
+	}
+
+	if len(templates) == 0 {
+		var catalog []models.RoleTemplate
+		if err := db.Preload("Permissions").Where("team_id IS NULL").Find(&catalog).Error; err != nil {
+			return err
+		}
+		for _, entry := range catalog {
+			template := models.RoleTemplate{
+				TeamID:      &teamID,
+				Name:        entry.Name,
+				Title:       entry.Title,
+				Description: entry.Description,
+				Position:    entry.Position,
+				Permissions: entry.Permissions,
+			}
+			if err := db.Create(&template).Error; err != nil {
+				return err
+			}
+			templates = append(templates, template)
+		}
+	}
+
+	for _, template := range templates {
+		var count int64
+		db.Model(&models.Role{}).Where("team_id = ? AND name = ?", teamID, template.Name).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		role := models.Role{
+			Name:        template.Name,
+			Description: template.Title,
+			TeamID:      teamID,
+			Position:    template.Position,
+			Permissions: template.Permissions,
+		}
+		if err := db.Create(&role).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncOwnerPermissions выдает ролям и шаблонам владельца права, добавленные в каталог позже
+func syncOwnerPermissions(db *gorm.DB) {
 	var permissions []models.Permission
-	db.Where("name IN ?", permissionNames).Find(&permissions)
+	db.Find(&permissions)
+
+	var roles []models.Role
+	db.Where("name = ?", models.RoleOwner).Find(&roles)
+	for i := range roles {
+		if err := db.Model(&roles[i]).Association("Permissions").Append(permissions); err != nil {
+			log.Printf("Failed to sync owner permissions for team %d: %v", roles[i].TeamID, err)
+		}
+	}
 
-	// Обновляем права роли
-	db.Model(role).Association("Permissions").Replace(permissions)
-	log.Printf("Updated permissions for role: %s", role.Name)
+	var templates []models.RoleTemplate
+	db.Where("name = ? AND team_id IS NOT NULL", models.RoleOwner).Find(&templates)
+	for i := range templates {
+		if err := db.Model(&templates[i]).Association("Permissions").Append(permissions); err != nil {
+			log.Printf("Failed to sync owner template permissions: %v", err)
+		}
+	}
 }
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetValorantCacheStats возвращает счетчики кэша ответов Valorant API по типам эндпоинтов
+func GetValorantCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, services.ValorantCache.Stats())
+}
+
+// GetSyncStatus возвращает состояние фоновой синхронизации: очередь, аккаунты в работе и отложенные после ошибок
+func GetSyncStatus(c *gin.Context) {
+	status, err := services.ValorantSync.Status()
+	if err != nil {
+		respondError(c, err, "Failed to fetch sync status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// ForceSyncAll ставит в очередь все привязанные аккаунты
+func ForceSyncAll(c *gin.Context) {
+	queued, err := services.ValorantSync.ForceAll()
+	if err != nil {
+		respondError(c, err, "Failed to queue sync")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"queued": queued})
+}
+
+// ForceSyncPlayer ставит аккаунт в начало очереди синхронизации
+func ForceSyncPlayer(c *gin.Context) {
+	playerID, err := strconv.ParseUint(c.Param("player_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	if err := services.ValorantSync.Force(uint(playerID)); err != nil {
+		respondError(c, err, "Failed to queue sync")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Sync queued"})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"valorant-app/middleware"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditEvents получает журнал команды с фильтрами и курсорной пагинацией
+func GetAuditEvents(c *gin.Context) {
+	team := middleware.CurrentTeam(c)
+
+	filter := services.AuditFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.From, filter.To = from, to
+
+	numbers := []struct {
+		param string
+		value *uint
+	}{
+		{"actor_id", &filter.ActorID},
+		{"target_id", &filter.TargetID},
+		{"cursor", &filter.Cursor},
+	}
+	for _, number := range numbers {
+		value := c.Query(number.param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + number.param})
+			return
+		}
+		*number.value = uint(parsed)
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, next, err := services.ListAuditEvents(team.ID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit events"})
+		return
+	}
+
+	response := gin.H{"events": events, "next_cursor": nil}
+	if next != 0 {
+		response["next_cursor"] = next
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest нестандартный статус nginx для запросов, прерванных клиентом
+const statusClientClosedRequest = 499
+
+// respondError переводит ошибку сервисного слоя в HTTP-ответ.
+// Неизвестные ошибки логируются и отдаются как 500 с сообщением fallback.
+func respondError(c *gin.Context, err error, fallback string) {
+	var permissionErr *services.PermissionError
+	var escalationErr *services.EscalationError
+	var valorantErr *services.ValorantAPIError
+	switch {
+	case errors.As(err, &permissionErr):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      err.Error(),
+			"code":       "permission_denied",
+			"permission": permissionErr.Permission,
+		})
+	case errors.As(err, &escalationErr):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      err.Error(),
+			"code":       "permission_escalation",
+			"permission": escalationErr.Permission,
+		})
+	case errors.Is(err, context.Canceled):
+		// Клиент закрыл соединение, отвечать некому
+		c.AbortWithStatus(statusClientClosedRequest)
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Printf("%s: %v", fallback, err)
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out", "code": "timeout"})
+	case errors.As(err, &valorantErr):
+		respondValorantError(c, err, valorantErr)
+	case errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrTeamNotFound),
+		errors.Is(err, services.ErrRoleNotFound),
+		errors.Is(err, services.ErrUserNotInTeam),
+		errors.Is(err, services.ErrPlayerNotLinked),
+		errors.Is(err, services.ErrInvitationNotFound),
+		errors.Is(err, services.ErrJoinRequestNotFound),
+		errors.Is(err, services.ErrRoleTemplateNotFound),
+		errors.Is(err, services.ErrOrganizationNotFound),
+		errors.Is(err, services.ErrOrganizationMemberNotFound),
+		errors.Is(err, services.ErrTeamNotInOrganization),
+		errors.Is(err, services.ErrPlayerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvitationExpired),
+		errors.Is(err, services.ErrInvitationRevoked),
+		errors.Is(err, services.ErrInvitationUsedUp):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvitationNotForUser),
+		errors.Is(err, services.ErrRoleHierarchy),
+		errors.Is(err, services.ErrNotTeamOwner),
+		errors.Is(err, services.ErrProtectedRole),
+		errors.Is(err, services.ErrOrganizationAccess),
+		errors.Is(err, services.ErrOrganizationViewAccess):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyInTeam),
+		errors.Is(err, services.ErrNotInTeam),
+		errors.Is(err, services.ErrJoinRequestExists),
+		errors.Is(err, services.ErrJoinRequestNotPending),
+		errors.Is(err, services.ErrAlreadyOwner),
+		errors.Is(err, services.ErrRoleNameTaken),
+		errors.Is(err, services.ErrOwnerCannotLeave),
+		errors.Is(err, services.ErrTeamInOrganization),
+		errors.Is(err, services.ErrTeamFull),
+		errors.Is(err, services.ErrStartersFull),
+		errors.Is(err, services.ErrRosterLimitTooLow),
+		errors.Is(err, services.ErrSyncInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownPermission),
+		errors.Is(err, services.ErrUnknownOrgRole),
+		errors.Is(err, services.ErrInvalidRosterSlot),
+		errors.Is(err, services.ErrInvalidInGameRole),
+		errors.Is(err, services.ErrInvalidStatus),
+		errors.Is(err, services.ErrTooManyMainAgents),
+		errors.Is(err, services.ErrInvalidCursor),
+		errors.Is(err, services.ErrInvalidRegion):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("%s: %v", fallback, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// respondValorantError переводит ошибку Valorant API в ответ: 404, 429 с Retry-After или 502.
+// Клиенту отдается только тип ошибки, полный текст с контекстом запроса пишется в лог.
+func respondValorantError(c *gin.Context, err error, apiErr *services.ValorantAPIError) {
+	switch {
+	case errors.Is(apiErr, services.ErrValorantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": apiErr.Kind.Error(), "code": "valorant_not_found"})
+	case errors.Is(apiErr, services.ErrValorantRateLimited):
+		if apiErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(apiErr.RetryAfter.Seconds()))))
+		}
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": apiErr.Kind.Error(), "code": "valorant_rate_limited"})
+	case errors.Is(apiErr, services.ErrValorantUnauthorized):
+		log.Printf("Valorant API request failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": apiErr.Kind.Error(), "code": "valorant_unauthorized"})
+	default:
+		log.Printf("Valorant API request failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": apiErr.Kind.Error(), "code": "valorant_unavailable"})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"valorant-app/middleware"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInvitation создает приглашение в команду
+func CreateInvitation(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	var request struct {
+		TargetUserID   *uint `json:"target_user_id"`
+		MaxUses        *int  `json:"max_uses"`
+		ExpiresInHours int   `json:"expires_in_hours"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// По умолчанию приглашение одноразовое
+	options := services.InvitationOptions{
+		TargetUserID: request.TargetUserID,
+		MaxUses:      1,
+		TTL:          time.Duration(request.ExpiresInHours) * time.Hour,
+	}
+	if request.MaxUses != nil {
+		if *request.MaxUses < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_uses must not be negative"})
+			return
+		}
+		options.MaxUses = *request.MaxUses
+	}
+
+	invitation, err := services.CreateInvitation(user, team.ID, options)
+	if err != nil {
+		respondError(c, err, "Failed to create invitation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetInvitations получает приглашения команды
+func GetInvitations(c *gin.Context) {
+	team := middleware.CurrentTeam(c)
+
+	invitations, err := services.ListInvitations(team.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation отзывает приглашение
+func RevokeInvitation(c *gin.Context) {
+	team := middleware.CurrentTeam(c)
+
+	invitationIDStr := c.Param("invitation_id")
+	invitationID, err := strconv.ParseUint(invitationIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	invitation, err := services.RevokeInvitation(middleware.CurrentUser(c), team.ID, uint(invitationID))
+	if err != nil {
+		respondError(c, err, "Failed to revoke invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
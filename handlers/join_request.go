@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"valorant-app/middleware"
+	"valorant-app/models"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateJoinRequest подает заявку на вступление в команду
+func CreateJoinRequest(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	var request struct {
+		Message string `json:"message" binding:"max=500"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	joinRequest, err := services.CreateJoinRequest(user, uint(teamID), request.Message)
+	if err != nil {
+		respondError(c, err, "Failed to create join request")
+		return
+	}
+
+	c.JSON(http.StatusCreated, joinRequest)
+}
+
+// GetJoinRequests получает заявки команды, по умолчанию — ожидающие рассмотрения
+func GetJoinRequests(c *gin.Context) {
+	team := middleware.CurrentTeam(c)
+
+	status := c.DefaultQuery("status", models.JoinRequestPending)
+	switch status {
+	case "all":
+		status = ""
+	case models.JoinRequestPending, models.JoinRequestApproved, models.JoinRequestRejected, models.JoinRequestWithdrawn:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	requests, err := services.ListJoinRequests(team.ID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch join requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// ApproveJoinRequest одобряет заявку и добавляет кандидата в команду
+func ApproveJoinRequest(c *gin.Context) {
+	reviewJoinRequest(c, services.ApproveJoinRequest, "Failed to approve join request")
+}
+
+// RejectJoinRequest отклоняет заявку
+func RejectJoinRequest(c *gin.Context) {
+	reviewJoinRequest(c, services.RejectJoinRequest, "Failed to reject join request")
+}
+
+// GetMyJoinRequests получает заявки текущего пользователя
+func GetMyJoinRequests(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	requests, err := services.ListUserJoinRequests(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch join requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// WithdrawJoinRequest отзывает заявку текущего пользователя
+func WithdrawJoinRequest(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	requestID, err := strconv.ParseUint(c.Param("request_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+
+	joinRequest, err := services.WithdrawJoinRequest(user, uint(requestID))
+	if err != nil {
+		respondError(c, err, "Failed to withdraw join request")
+		return
+	}
+
+	c.JSON(http.StatusOK, joinRequest)
+}
+
+func reviewJoinRequest(c *gin.Context, review func(*models.User, uint) (*models.JoinRequest, error), fallback string) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	requestID, err := strconv.ParseUint(c.Param("request_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+
+	// Заявка должна относиться к команде из пути
+	existing, err := services.FindJoinRequest(uint(requestID))
+	if err == nil && existing.TeamID != team.ID {
+		err = services.ErrJoinRequestNotFound
+	}
+	if err != nil {
+		respondError(c, err, fallback)
+		return
+	}
+
+	joinRequest, err := review(user, existing.ID)
+	if err != nil {
+		respondError(c, err, fallback)
+		return
+	}
+
+	c.JSON(http.StatusOK, joinRequest)
+}
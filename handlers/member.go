@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"valorant-app/middleware"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTeamMembers возвращает участников команды со статусом и позицией в составе
+func GetTeamMembers(c *gin.Context) {
+	team := middleware.CurrentTeam(c)
+
+	memberships, err := services.ListTeamMemberships(team.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, memberships)
+}
+
+// KickMember исключает участника из команды
+func KickMember(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if _, err := services.KickMember(user, team.ID, uint(memberID)); err != nil {
+		respondError(c, err, "Failed to kick member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed from team"})
+}
+
+// DisbandTeam распускает команду; доступно только владельцу
+func DisbandTeam(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if _, err := services.DisbandTeam(user, uint(teamID)); err != nil {
+		respondError(c, err, "Failed to disband team")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team disbanded"})
+}
+
+// TransferOwnership передает команду другому участнику
+func TransferOwnership(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	var request struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, _, err := services.TransferOwnership(user, uint(teamID), request.UserID)
+	if err != nil {
+		respondError(c, err, "Failed to transfer ownership")
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
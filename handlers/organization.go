@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"valorant-app/middleware"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOrganization создает организацию; текущий пользователь становится владельцем
+func CreateOrganization(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	var request struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := services.CreateOrganization(user, request.Name, request.Description)
+	if err != nil {
+		respondError(c, err, "Failed to create organization")
+		return
+	}
+
+	c.JSON(http.StatusCreated, organization)
+}
+
+// GetOrganization возвращает организацию с командами и ролями участников
+func GetOrganization(c *gin.Context) {
+	organizationID, err := strconv.ParseUint(c.Param("org_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	if err := services.RequireOrganizationViewer(middleware.CurrentUser(c), uint(organizationID)); err != nil {
+		respondError(c, err, "Failed to fetch organization")
+		return
+	}
+
+	organization, err := services.GetOrganization(uint(organizationID))
+	if err != nil {
+		respondError(c, err, "Failed to fetch organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// GetMyOrganizations возвращает организации текущего пользователя
+func GetMyOrganizations(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	organizations, err := services.ListUserOrganizations(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
+
+// AddOrganizationTeam добавляет команду текущего пользователя в организацию
+func AddOrganizationTeam(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	organizationID, err := strconv.ParseUint(c.Param("org_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var request struct {
+		TeamID uint `json:"team_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := services.AddOrganizationTeam(user, uint(organizationID), request.TeamID)
+	if err != nil {
+		respondError(c, err, "Failed to add team to organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// RemoveOrganizationTeam выводит команду из организации
+func RemoveOrganizationTeam(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	organizationID, err := strconv.ParseUint(c.Param("org_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if err := services.RemoveOrganizationTeam(user, uint(organizationID), uint(teamID)); err != nil {
+		respondError(c, err, "Failed to remove team from organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team removed from organization"})
+}
+
+// SetOrganizationMember назначает пользователю роль организации
+func SetOrganizationMember(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	organizationID, err := strconv.ParseUint(c.Param("org_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var request struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := services.SetOrganizationMember(user, uint(organizationID), uint(memberID), request.Role)
+	if err != nil {
+		respondError(c, err, "Failed to set organization role")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveOrganizationMember снимает с пользователя роль организации
+func RemoveOrganizationMember(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	organizationID, err := strconv.ParseUint(c.Param("org_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := services.RemoveOrganizationMember(user, uint(organizationID), uint(memberID)); err != nil {
+		respondError(c, err, "Failed to remove organization role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organization role removed"})
+}
+
+// GetOrganizationStats сводная статистика команд организации.
+// Поддерживает те же фильтры, что и статистика команды.
+func GetOrganizationStats(c *gin.Context) {
+	organizationID, err := strconv.ParseUint(c.Param("org_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.RequireOrganizationViewer(middleware.CurrentUser(c), uint(organizationID)); err != nil {
+		respondError(c, err, "Failed to aggregate organization stats")
+		return
+	}
+
+	stats, err := services.GetOrganizationStats(uint(organizationID), filter)
+	if err != nil {
+		respondError(c, err, "Failed to aggregate organization stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"valorant-app/middleware"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPermissions получает каталог прав для редактора ролей.
+// Язык берется из параметра lang, иначе из профиля Telegram пользователя.
+func GetPermissions(c *gin.Context) {
+	lang := c.Query("lang")
+	if lang == "" {
+		lang = middleware.CurrentUser(c).LanguageCode
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+
+	c.JSON(http.StatusOK, services.PermissionCatalog(lang))
+}
@@ -3,8 +3,8 @@ package handlers
 import (
 	"net/http"
 	"strconv"
-	"valorant-app/database"
-	"valorant-app/models"
+	"valorant-app/middleware"
+	"valorant-app/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,9 +18,8 @@ func GetTeamRoles(c *gin.Context) {
 		return
 	}
 
-	var roles []models.Role
-	result := database.DB.Preload("Permissions").Where("team_id = ?", teamID).Find(&roles)
-	if result.Error != nil {
+	roles, err := services.GetTeamRoles(uint(teamID))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
 		return
 	}
@@ -30,6 +29,8 @@ func GetTeamRoles(c *gin.Context) {
 
 // CreateRole создает новую роль в команде
 func CreateRole(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
 	teamIDStr := c.Param("team_id")
 	teamID, err := strconv.ParseUint(teamIDStr, 10, 32)
 	if err != nil {
@@ -37,16 +38,20 @@ func CreateRole(c *gin.Context) {
 		return
 	}
 
-	var role models.Role
-	if err := c.ShouldBindJSON(&role); err != nil {
+	var request struct {
+		Name        string   `json:"name" binding:"required"`
+		Description string   `json:"description"`
+		Position    int      `json:"position" binding:"min=0"`
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	role.TeamID = uint(teamID)
-	result := database.DB.Create(&role)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role"})
+	role, err := services.CreateRole(user, uint(teamID), request.Name, request.Description, request.Position, request.Permissions)
+	if err != nil {
+		respondError(c, err, "Failed to create role")
 		return
 	}
 
@@ -55,6 +60,36 @@ func CreateRole(c *gin.Context) {
 
 // AssignRole назначает роль пользователю
 func AssignRole(c *gin.Context) {
+	teamID, userID, roleID, ok := parseMemberRoleParams(c)
+	if !ok {
+		return
+	}
+
+	if err := services.AssignRole(middleware.CurrentUser(c), teamID, userID, roleID); err != nil {
+		respondError(c, err, "Failed to assign role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role assigned successfully"})
+}
+
+// RemoveRole убирает роль у пользователя
+func RemoveRole(c *gin.Context) {
+	teamID, userID, roleID, ok := parseMemberRoleParams(c)
+	if !ok {
+		return
+	}
+
+	if err := services.RemoveRole(middleware.CurrentUser(c), teamID, userID, roleID); err != nil {
+		respondError(c, err, "Failed to remove role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role removed successfully"})
+}
+
+// GetUserRoles получает роли пользователя в команде
+func GetUserRoles(c *gin.Context) {
 	teamIDStr := c.Param("team_id")
 	teamID, err := strconv.ParseUint(teamIDStr, 10, 32)
 	if err != nil {
@@ -69,108 +104,188 @@ func AssignRole(c *gin.Context) {
 		return
 	}
 
-	roleIDStr := c.Param("role_id")
-	roleID, err := strconv.ParseUint(roleIDStr, 10, 32)
+	roles, err := services.GetUserRoles(uint(teamID), uint(userID))
+	if err != nil {
+		respondError(c, err, "Failed to fetch roles")
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// UpdateRole изменяет название, описание, позицию и права роли
+func UpdateRole(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	roleID, err := strconv.ParseUint(c.Param("role_id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
 		return
 	}
 
-	// Проверяем, что роль принадлежит команде
-	var role models.Role
-	result := database.DB.Where("id = ? AND team_id = ?", roleID, teamID).First(&role)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found in this team"})
+	var request struct {
+		Name        *string  `json:"name" binding:"omitempty,min=1"`
+		Description *string  `json:"description"`
+		Position    *int     `json:"position" binding:"omitempty,min=0"`
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Проверяем, что пользователь в команде
-	var user models.User
-	result = database.DB.Where("id = ? AND team_id = ?", userID, teamID).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found in this team"})
+	role, err := services.UpdateRole(user, team.ID, uint(roleID), services.RoleUpdate{
+		Name:        request.Name,
+		Description: request.Description,
+		Position:    request.Position,
+		Permissions: request.Permissions,
+	})
+	if err != nil {
+		respondError(c, err, "Failed to update role")
 		return
 	}
 
-	// Назначаем роль
-	err = database.DB.Model(&user).Association("Roles").Append(&role)
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole удаляет роль команды
+func DeleteRole(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	roleID, err := strconv.ParseUint(c.Param("role_id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign role"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Role assigned successfully"})
+	if err := services.DeleteRole(user, team.ID, uint(roleID)); err != nil {
+		respondError(c, err, "Failed to delete role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
 }
 
-// RemoveRole убирает роль у пользователя
-func RemoveRole(c *gin.Context) {
-	teamIDStr := c.Param("team_id")
-	teamID, err := strconv.ParseUint(teamIDStr, 10, 32)
+// AddRolePermissions добавляет роли права по названиям
+func AddRolePermissions(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	roleID, err := strconv.ParseUint(c.Param("role_id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
 		return
 	}
 
-	userIDStr := c.Param("user_id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	var request struct {
+		Permissions []string `json:"permissions" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := services.AddRolePermissions(user, team.ID, uint(roleID), request.Permissions)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		respondError(c, err, "Failed to add permissions")
 		return
 	}
 
-	roleIDStr := c.Param("role_id")
-	roleID, err := strconv.ParseUint(roleIDStr, 10, 32)
+	c.JSON(http.StatusOK, role)
+}
+
+// RemoveRolePermission снимает с роли право
+func RemoveRolePermission(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	roleID, err := strconv.ParseUint(c.Param("role_id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
 		return
 	}
 
-	var user models.User
-	result := database.DB.Where("id = ? AND team_id = ?", userID, teamID).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found in this team"})
+	role, err := services.RemoveRolePermission(user, team.ID, uint(roleID), c.Param("permission"))
+	if err != nil {
+		respondError(c, err, "Failed to remove permission")
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// SetRolePosition перемещает роль в иерархии
+func SetRolePosition(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	roleID, err := strconv.ParseUint(c.Param("role_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
 		return
 	}
 
-	var role models.Role
-	result = database.DB.Where("id = ? AND team_id = ?", roleID, teamID).First(&role)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found in this team"})
+	var request struct {
+		Position *int `json:"position" binding:"required,min=0"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Убираем роль
-	err = database.DB.Model(&user).Association("Roles").Delete(&role)
+	role, err := services.SetRolePosition(user, team.ID, uint(roleID), *request.Position)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove role"})
+		respondError(c, err, "Failed to move role")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Role removed successfully"})
+	c.JSON(http.StatusOK, role)
 }
 
-// GetUserRoles получает роли пользователя в команде
-func GetUserRoles(c *gin.Context) {
-	teamIDStr := c.Param("team_id")
-	teamID, err := strconv.ParseUint(teamIDStr, 10, 32)
+// ReorderRoles переставляет роли команды в порядке role_ids (от старшей к младшей)
+func ReorderRoles(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	var request struct {
+		RoleIDs []uint `json:"role_ids" binding:"required,min=2"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	roles, err := services.ReorderRoles(user, team.ID, request.RoleIDs)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondError(c, err, "Failed to reorder roles")
 		return
 	}
 
-	userIDStr := c.Param("user_id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	c.JSON(http.StatusOK, roles)
+}
+
+// parseMemberRoleParams разбирает :team_id, :user_id и :role_id.
+// При ошибке отвечает 400 и возвращает ok == false.
+func parseMemberRoleParams(c *gin.Context) (teamID, userID, roleID uint, ok bool) {
+	team, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return 0, 0, 0, false
+	}
+
+	user, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
+		return 0, 0, 0, false
 	}
 
-	var user models.User
-	result := database.DB.Preload("Roles", "team_id = ?", teamID).Where("id = ? AND team_id = ?", userID, teamID).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found in this team"})
-		return
+	role, err := strconv.ParseUint(c.Param("role_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return 0, 0, 0, false
 	}
 
-	c.JSON(http.StatusOK, user.Roles)
+	return uint(team), uint(user), uint(role), true
 }
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"valorant-app/middleware"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoleTemplates получает шаблоны ролей команды
+func GetRoleTemplates(c *gin.Context) {
+	team := middleware.CurrentTeam(c)
+
+	templates, err := services.ListRoleTemplates(team.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// UpdateRoleTemplate изменяет шаблон роли команды; доступно только владельцу
+func UpdateRoleTemplate(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	templateID, err := strconv.ParseUint(c.Param("template_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	var request struct {
+		Title       *string  `json:"title"`
+		Description *string  `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := services.UpdateRoleTemplate(user, team.ID, uint(templateID), services.RoleTemplateUpdate{
+		Title:       request.Title,
+		Description: request.Description,
+		Permissions: request.Permissions,
+	})
+	if err != nil {
+		respondError(c, err, "Failed to update role template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"valorant-app/middleware"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoster возвращает состав команды по позициям
+func GetRoster(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	roster, err := services.GetRoster(uint(teamID))
+	if err != nil {
+		respondError(c, err, "Failed to fetch roster")
+		return
+	}
+
+	c.JSON(http.StatusOK, roster)
+}
+
+// UpdateRosterEntry изменяет позицию, статус, игровую роль и агентов участника
+func UpdateRosterEntry(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var request struct {
+		RosterSlot *string  `json:"roster_slot"`
+		Status     *string  `json:"status"`
+		InGameRole *string  `json:"in_game_role"`
+		MainAgents []string `json:"main_agents"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	membership, err := services.UpdateRosterEntry(user, uint(teamID), uint(memberID), services.RosterUpdate{
+		RosterSlot: request.RosterSlot,
+		Status:     request.Status,
+		InGameRole: request.InGameRole,
+		MainAgents: request.MainAgents,
+	})
+	if err != nil {
+		respondError(c, err, "Failed to update roster")
+		return
+	}
+
+	c.JSON(http.StatusOK, membership)
+}
+
+// SetRosterLimit задает лимит участников команды
+func SetRosterLimit(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	var request struct {
+		RosterLimit *int `json:"roster_limit" binding:"required,min=0"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := services.SetRosterLimit(user, team.ID, *request.RosterLimit)
+	if err != nil {
+		respondError(c, err, "Failed to update roster limit")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
@@ -3,85 +3,202 @@ package handlers
 import (
 	"net/http"
 	"strconv"
-	"valorant-app/database"
+	"strings"
+	"valorant-app/middleware"
 	"valorant-app/models"
+	"valorant-app/services"
+	"valorant-app/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// GetTeams список команд с поиском, фильтрами, сортировкой и курсорной пагинацией
 func GetTeams(c *gin.Context) {
-	var teams []models.Team
-	result := database.DB.Preload("Members").Find(&teams)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teams"})
+	filter := services.TeamFilter{
+		Search:         c.Query("q"),
+		Region:         strings.ToLower(c.Query("region")),
+		Cursor:         c.Query("cursor"),
+		IncludeMembers: c.Query("include") == "members",
+	}
+	if filter.Region != "" && !models.IsValorantRegion(filter.Region) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidRegion.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, teams)
+	if value := c.Query("recruiting"); value != "" {
+		recruiting, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recruiting"})
+			return
+		}
+		filter.Recruiting = &recruiting
+	}
+
+	// Границы среднего ранга задаются названием ранга, например "Gold 1"
+	ranks := []struct {
+		param string
+		value **int
+	}{
+		{"min_rank", &filter.MinRank},
+		{"max_rank", &filter.MaxRank},
+	}
+	for _, rank := range ranks {
+		value := c.Query(rank.param)
+		if value == "" {
+			continue
+		}
+		tier := models.RankTierIndex(value)
+		if tier < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + rank.param})
+			return
+		}
+		*rank.value = &tier
+	}
+
+	numbers := []struct {
+		param string
+		value **int
+	}{
+		{"min_members", &filter.MinMembers},
+		{"max_members", &filter.MaxMembers},
+	}
+	for _, number := range numbers {
+		value := c.Query(number.param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + number.param})
+			return
+		}
+		*number.value = &parsed
+	}
+
+	// Сортировка: created, name, members, rank; префикс "-" — по убыванию
+	if sort := c.Query("sort"); sort != "" {
+		filter.Sort = strings.TrimPrefix(sort, "-")
+		filter.Descending = strings.HasPrefix(sort, "-")
+		if !services.IsTeamSort(filter.Sort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort"})
+			return
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	teams, next, err := services.ListTeams(filter)
+	if err != nil {
+		respondError(c, err, "Failed to fetch teams")
+		return
+	}
+
+	// Участников отдаем только по командам, где у пользователя есть право view_members
+	if filter.IncludeMembers {
+		user := middleware.CurrentUser(c)
+		for i := range teams {
+			if !utils.HasTeamPermission(user.ID, teams[i].ID, models.PermissionViewMembers) {
+				teams[i].Members = nil
+			}
+		}
+	}
+
+	response := gin.H{"teams": teams, "next_cursor": nil}
+	if next != "" {
+		response["next_cursor"] = next
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 func GetTeam(c *gin.Context) {
-	idStr := c.Param("id")
+	idStr := c.Param("team_id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
 		return
 	}
 
-	var team models.Team
-	result := database.DB.Preload("Members").First(&team, id)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+	team, err := services.GetTeam(uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to fetch team")
 		return
 	}
 
+	// Состав команды видят только те, кому доступен GET /teams/:team_id/members
+	if !utils.HasTeamPermission(middleware.CurrentUser(c).ID, team.ID, models.PermissionViewMembers) {
+		team.Members = nil
+	}
+
 	c.JSON(http.StatusOK, team)
 }
 
-func CreateTeam(c *gin.Context) {
-	var team models.Team
-	if err := c.ShouldBindJSON(&team); err != nil {
+// UpdateTeam изменение названия, описания, региона и статуса набора команды
+func UpdateTeam(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	team := middleware.CurrentTeam(c)
+
+	var request struct {
+		Name        *string `json:"name" binding:"omitempty,min=1"`
+		Description *string `json:"description"`
+		Region      *string `json:"region"`
+		Recruiting  *bool   `json:"recruiting"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	result := database.DB.Create(&team)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team"})
-		return
+	update := services.TeamUpdate{
+		Name:        request.Name,
+		Description: request.Description,
+		Region:      request.Region,
+		Recruiting:  request.Recruiting,
+	}
+	if update.Region != nil {
+		region := strings.ToLower(*update.Region)
+		update.Region = &region
 	}
 
-	// Создаем роль владельца для команды
-	ownerRole := models.Role{
-		Name:        models.RoleOwner,
-		Description: "Владелец команды",
-		TeamID:      team.ID,
+	updated, err := services.UpdateTeam(user, team.ID, update)
+	if err != nil {
+		respondError(c, err, "Failed to update team")
+		return
 	}
 
-	// Находим все права для роли владельца
-	var permissions []models.Permission
-	database.DB.Find(&permissions)
-	ownerRole.Permissions = permissions
+	c.JSON(http.StatusOK, updated)
+}
+
+func CreateTeam(c *gin.Context) {
+	user := middleware.CurrentUser(c)
 
-	database.DB.Create(&ownerRole)
+	var request struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Назначаем роль владельца создателю команды
-	if team.CreatedBy != 0 {
-		var user models.User
-		database.DB.First(&user, team.CreatedBy)
-		database.DB.Model(&user).Association("Roles").Append(&ownerRole)
+	team, err := services.CreateTeam(user, request.Name, request.Description)
+	if err != nil {
+		respondError(c, err, "Failed to create team")
+		return
 	}
 
 	c.JSON(http.StatusCreated, team)
 }
 
+// JoinTeam вступление в команду по коду приглашения
 func JoinTeam(c *gin.Context) {
-	telegramIDStr := c.Param("telegram_id")
-	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telegram ID"})
-		return
-	}
+	user := middleware.CurrentUser(c)
 
 	teamIDStr := c.Param("team_id")
 	teamID, err := strconv.ParseUint(teamIDStr, 10, 32)
@@ -90,74 +207,44 @@ func JoinTeam(c *gin.Context) {
 		return
 	}
 
-	// Check if user exists
-	var user models.User
-	result := database.DB.Where("telegram_id = ?", telegramID).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
+	var request struct {
+		Token string `json:"token" binding:"required"`
 	}
-
-	// Check if team exists
-	var team models.Team
-	result = database.DB.First(&team, teamID)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Update user's team
-	user.TeamID = &team.ID
-	result = database.DB.Save(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join team"})
+	// Приглашение должно относиться к команде из пути
+	invitation, err := services.FindInvitation(request.Token)
+	if err == nil && invitation.TeamID != uint(teamID) {
+		err = services.ErrInvitationNotFound
+	}
+	if err != nil {
+		respondError(c, err, "Failed to join team")
 		return
 	}
 
-	// Находим или создаем роль участника для команды
-	var memberRole models.Role
-	result = database.DB.Where("name = ? AND team_id = ?", models.RoleMember, team.ID).First(&memberRole)
-	if result.Error != nil {
-		// Создаем роль участника для команды
-		memberRole = models.Role{
-			Name:        models.RoleMember,
-			Description: "Участник команды",
-			TeamID:      team.ID,
-		}
-
-		// Находим права для роли участника
-		var permissions []models.Permission
-		database.DB.Where("name IN ?", []string{models.PermissionViewMembers, models.PermissionEditProfile}).Find(&permissions)
-		memberRole.Permissions = permissions
-
-		database.DB.Create(&memberRole)
+	if _, err := services.RedeemInvitation(user, request.Token); err != nil {
+		respondError(c, err, "Failed to join team")
+		return
 	}
 
-	// Назначаем роль участника
-	database.DB.Model(&user).Association("Roles").Append(&memberRole)
-
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully joined team"})
 }
 
+// LeaveTeam выход из команды из пути
 func LeaveTeam(c *gin.Context) {
-	telegramIDStr := c.Param("telegram_id")
-	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telegram ID"})
-		return
-	}
+	user := middleware.CurrentUser(c)
 
-	var user models.User
-	result := database.DB.Where("telegram_id = ?", telegramID).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
 		return
 	}
 
-	user.TeamID = nil
-	result = database.DB.Save(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave team"})
+	if err := services.LeaveTeam(user, uint(teamID)); err != nil {
+		respondError(c, err, "Failed to leave team")
 		return
 	}
 
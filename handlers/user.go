@@ -2,23 +2,20 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 	"valorant-app/database"
+	"valorant-app/middleware"
 	"valorant-app/models"
+	"valorant-app/services"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetUser(c *gin.Context) {
-	telegramIDStr := c.Param("telegram_id")
-	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telegram ID"})
-		return
-	}
+// GetCurrentUser возвращает аутентифицированного пользователя
+func GetCurrentUser(c *gin.Context) {
+	current := middleware.CurrentUser(c)
 
 	var user models.User
-	result := database.DB.Preload("Team").Where("telegram_id = ?", telegramID).First(&user)
+	result := database.DB.Preload("Teams").First(&user, current.ID)
 	if result.Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -27,50 +24,41 @@ func GetUser(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-func CreateUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Check if user already exists
-	var existingUser models.User
-	result := database.DB.Where("telegram_id = ?", user.TelegramID).First(&existingUser)
-	if result.Error == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
-		return
-	}
+// GetMyTeams возвращает членства пользователя в командах
+func GetMyTeams(c *gin.Context) {
+	user := middleware.CurrentUser(c)
 
-	result = database.DB.Create(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	memberships, err := services.ListUserMemberships(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teams"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, user)
+	c.JSON(http.StatusOK, memberships)
 }
 
-func UpdateUser(c *gin.Context) {
-	telegramIDStr := c.Param("telegram_id")
-	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telegram ID"})
-		return
-	}
+// UpdateCurrentUser обновляет профиль аутентифицированного пользователя
+func UpdateCurrentUser(c *gin.Context) {
+	user := middleware.CurrentUser(c)
 
-	var user models.User
-	result := database.DB.Where("telegram_id = ?", telegramID).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	// Username принадлежит Telegram и обновляется при каждом входе, поэтому здесь не меняется
+	var request struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	user.FirstName = request.FirstName
+	user.LastName = request.LastName
+
+	result := database.DB.Model(user).Select("first_name", "last_name").Updates(user)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
 
-	database.DB.Save(&user)
 	c.JSON(http.StatusOK, user)
 }
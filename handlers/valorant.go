@@ -1,22 +1,22 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 	"valorant-app/database"
+	"valorant-app/middleware"
 	"valorant-app/models"
+	"valorant-app/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AddValorantPlayer добавляет Valorant аккаунт к пользователю
 func AddValorantPlayer(c *gin.Context) {
-	telegramIDStr := c.Param("telegram_id")
-	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telegram ID"})
-		return
-	}
+	user := middleware.CurrentUser(c)
 
 	var request struct {
 		GameName string `json:"game_name" binding:"required"`
@@ -29,25 +29,9 @@ func AddValorantPlayer(c *gin.Context) {
 		return
 	}
 
-	// Находим пользователя
-	var user models.User
-	result := database.DB.Where("telegram_id = ?", telegramID).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
-	}
-
-	// Создаем Valorant игрока
-	valorantPlayer := models.ValorantPlayer{
-		UserID:   user.ID,
-		GameName: request.GameName,
-		Tag:      request.Tag,
-		Region:   request.Region,
-	}
-
-	result = database.DB.Create(&valorantPlayer)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Valorant player"})
+	valorantPlayer, err := services.LinkValorantPlayer(user, request.GameName, request.Tag, request.Region)
+	if err != nil {
+		respondError(c, err, "Failed to create Valorant player")
 		return
 	}
 
@@ -56,15 +40,10 @@ func AddValorantPlayer(c *gin.Context) {
 
 // GetValorantPlayer получает Valorant аккаунт пользователя
 func GetValorantPlayer(c *gin.Context) {
-	telegramIDStr := c.Param("telegram_id")
-	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telegram ID"})
-		return
-	}
+	current := middleware.CurrentUser(c)
 
 	var user models.User
-	result := database.DB.Preload("ValorantPlayers").Where("telegram_id = ?", telegramID).First(&user)
+	result := database.DB.Preload("ValorantPlayers").First(&user, current.ID)
 	if result.Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -82,9 +61,8 @@ func GetTeamValorantPlayers(c *gin.Context) {
 		return
 	}
 
-	var players []models.ValorantPlayer
-	result := database.DB.Preload("User").Where("user_id IN (SELECT id FROM users WHERE team_id = ?)", teamID).Find(&players)
-	if result.Error != nil {
+	players, err := services.GetTeamPlayers(uint(teamID))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team players"})
 		return
 	}
@@ -92,56 +70,123 @@ func GetTeamValorantPlayers(c *gin.Context) {
 	c.JSON(http.StatusOK, players)
 }
 
-// SyncPlayerData синхронизирует данные игрока с Valorant API
+// syncRequestTimeout предельное время синхронизации по запросу пользователя
+const syncRequestTimeout = 90 * time.Second
+
+// SyncPlayerData синхронизирует данные игрока с Valorant API.
+// Синхронизация прерывается, если клиент закрыл соединение или истек syncRequestTimeout.
 func SyncPlayerData(c *gin.Context) {
-	telegramIDStr := c.Param("telegram_id")
-	_, err := strconv.ParseInt(telegramIDStr, 10, 64)
+	user := middleware.CurrentUser(c)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), syncRequestTimeout)
+	defer cancel()
+
+	syncResult, err := services.SyncUserPlayers(ctx, services.ValorantClient, user)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telegram ID"})
+		respondError(c, err, "Failed to sync player data")
 		return
 	}
 
-	// TODO: Реализовать синхронизацию с Valorant API
-	// 1. Получить данные игрока из API
-	// 2. Обновить данные в базе данных
-	// 3. Получить матчи игрока
-	// 4. Сохранить статистику
-
-	c.JSON(http.StatusOK, gin.H{"message": "Player data synced successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Player data synced successfully",
+		"new_matches":     syncResult.NewMatches,
+		"skipped_matches": syncResult.SkippedMatches,
+		"failed_matches":  syncResult.FailedMatches,
+		"failed_accounts": syncResult.FailedAccounts,
+		"accounts":        syncResult.Accounts,
+	})
 }
 
 // GetPlayerStats получает статистику игрока
 func GetPlayerStats(c *gin.Context) {
-	telegramIDStr := c.Param("telegram_id")
-	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telegram ID"})
-		return
-	}
+	user := middleware.CurrentUser(c)
 
-	var user models.User
-	result := database.DB.Preload("ValorantPlayers.Stats").Where("telegram_id = ?", telegramID).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	players, err := services.GetUserPlayers(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player stats"})
 		return
 	}
 
-	c.JSON(http.StatusOK, user.ValorantPlayers)
+	c.JSON(http.StatusOK, players)
 }
 
-// GetTeamStats получает статистику команды
+// GetTeamStats получает статистику команды.
+// Поддерживает фильтры from/to (YYYY-MM-DD или RFC3339) и mode.
 func GetTeamStats(c *gin.Context) {
 	teamIDStr := c.Param("team_id")
-	_, err := strconv.ParseUint(teamIDStr, 10, 32)
+	teamID, err := strconv.ParseUint(teamIDStr, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
 		return
 	}
 
-	// TODO: Реализовать агрегацию статистики команды
-	// 1. Получить всех игроков команды
-	// 2. Агрегировать их статистику
-	// 3. Вернуть общую статистику команды
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := services.GetTeam(uint(teamID)); err != nil {
+		respondError(c, err, "Failed to aggregate team stats")
+		return
+	}
+
+	stats, err := services.GetTeamStats(uint(teamID), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to aggregate team stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// parseStatsFilter разбирает параметры фильтра статистики из query
+func parseStatsFilter(c *gin.Context) (services.StatsFilter, error) {
+	filter := services.StatsFilter{Mode: c.Query("mode")}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.From, filter.To = from, to
+
+	return filter, nil
+}
+
+// parseDateRange разбирает параметры from и to запроса
+func parseDateRange(c *gin.Context) (from, to *time.Time, err error) {
+	if value := c.Query("from"); value != "" {
+		date, _, err := parseFilterDate(value)
+		if err != nil {
+			return nil, nil, errors.New("Invalid from date")
+		}
+		from = &date
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Team stats endpoint - to be implemented"})
+	if value := c.Query("to"); value != "" {
+		date, dateOnly, err := parseFilterDate(value)
+		if err != nil {
+			return nil, nil, errors.New("Invalid to date")
+		}
+		// Дата без времени включает весь день
+		if dateOnly {
+			date = date.AddDate(0, 0, 1)
+		}
+		to = &date
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
+
+// parseFilterDate принимает дату в формате YYYY-MM-DD или RFC3339
+func parseFilterDate(value string) (time.Time, bool, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, true, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	return date, false, err
 }
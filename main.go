@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 	"valorant-app/bot"
 	"valorant-app/config"
 	"valorant-app/database"
 	"valorant-app/handlers"
+	"valorant-app/middleware"
+	"valorant-app/models"
+	"valorant-app/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,11 +22,19 @@ func main() {
 	// Initialize database
 	database.InitDB(cfg)
 
+	// Initialize Valorant API client
+	services.InitValorantClient(cfg)
+
+	// Фоновая синхронизация привязанных аккаунтов Valorant
+	services.StartSyncScheduler(context.Background(), cfg)
+
 	// Initialize bot
 	telegramBot, err := bot.NewBot(cfg)
 	if err != nil {
 		log.Fatal("Failed to create bot:", err)
 	}
+	services.BotUsername = telegramBot.API.Self.UserName
+	services.SetNotifier(telegramBot)
 
 	// Check if we should use webhook or polling
 	useWebhook := cfg.WebhookURL != "" && cfg.WebhookURL != "http://localhost:8080"
@@ -53,7 +66,8 @@ func main() {
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Telegram-Init-Data, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -65,33 +79,83 @@ func main() {
 
 	// API routes
 	api := r.Group("/api")
+	api.Use(middleware.RequestID())
+	api.Use(middleware.TelegramAuth(cfg.TelegramBotToken, time.Duration(cfg.AuthMaxAge)*time.Second))
 	{
-		// User routes
-		api.GET("/users/:telegram_id", handlers.GetUser)
-		api.POST("/users", handlers.CreateUser)
-		api.PUT("/users/:telegram_id", handlers.UpdateUser)
+		// Current user routes
+		api.GET("/me", handlers.GetCurrentUser)
+		api.PUT("/me", handlers.UpdateCurrentUser)
+		api.GET("/me/teams", handlers.GetMyTeams)
 
 		// Team routes
 		api.GET("/teams", handlers.GetTeams)
-		api.GET("/teams/:id", handlers.GetTeam)
+		api.GET("/teams/:team_id", handlers.GetTeam)
 		api.POST("/teams", handlers.CreateTeam)
-		api.POST("/teams/:team_id/join/:telegram_id", handlers.JoinTeam)
-		api.POST("/teams/leave/:telegram_id", handlers.LeaveTeam)
+		api.PUT("/teams/:team_id", middleware.RequirePermission(models.PermissionManageTeam), handlers.UpdateTeam)
+		api.DELETE("/teams/:team_id", handlers.DisbandTeam)
+		api.POST("/teams/:team_id/join", handlers.JoinTeam)
+		api.POST("/teams/:team_id/leave", handlers.LeaveTeam)
+		api.GET("/teams/:team_id/members", middleware.RequirePermission(models.PermissionViewMembers), handlers.GetTeamMembers)
+		api.DELETE("/teams/:team_id/members/:user_id", middleware.RequirePermission(models.PermissionKickMembers), handlers.KickMember)
+		api.GET("/teams/:team_id/roster", middleware.RequirePermission(models.PermissionViewMembers), handlers.GetRoster)
+		api.PUT("/teams/:team_id/roster", middleware.RequirePermission(models.PermissionManageTeam), handlers.SetRosterLimit)
+		api.PUT("/teams/:team_id/roster/:user_id", handlers.UpdateRosterEntry)
+		api.POST("/teams/:team_id/transfer", handlers.TransferOwnership)
+
+		// Invitation routes
+		api.POST("/teams/:team_id/invitations", middleware.RequirePermission(models.PermissionInviteMembers), handlers.CreateInvitation)
+		api.GET("/teams/:team_id/invitations", middleware.RequirePermission(models.PermissionInviteMembers), handlers.GetInvitations)
+		api.DELETE("/teams/:team_id/invitations/:invitation_id", middleware.RequirePermission(models.PermissionInviteMembers), handlers.RevokeInvitation)
+
+		// Заявки на вступление
+		api.POST("/teams/:team_id/join-requests", handlers.CreateJoinRequest)
+		api.GET("/teams/:team_id/join-requests", middleware.RequirePermission(models.PermissionInviteMembers), handlers.GetJoinRequests)
+		api.POST("/teams/:team_id/join-requests/:request_id/approve", middleware.RequirePermission(models.PermissionInviteMembers), handlers.ApproveJoinRequest)
+		api.POST("/teams/:team_id/join-requests/:request_id/reject", middleware.RequirePermission(models.PermissionInviteMembers), handlers.RejectJoinRequest)
+		api.GET("/me/join-requests", handlers.GetMyJoinRequests)
+		api.POST("/me/join-requests/:request_id/withdraw", handlers.WithdrawJoinRequest)
 
 		// Role routes
-		api.GET("/teams/:team_id/roles", handlers.GetTeamRoles)
-		api.POST("/teams/:team_id/roles", handlers.CreateRole)
-		api.POST("/teams/:team_id/users/:user_id/roles/:role_id", handlers.AssignRole)
-		api.DELETE("/teams/:team_id/users/:user_id/roles/:role_id", handlers.RemoveRole)
-		api.GET("/teams/:team_id/users/:user_id/roles", handlers.GetUserRoles)
+		api.GET("/permissions", handlers.GetPermissions)
+		api.GET("/teams/:team_id/roles", middleware.RequirePermission(models.PermissionViewMembers), handlers.GetTeamRoles)
+		api.POST("/teams/:team_id/roles", middleware.RequirePermission(models.PermissionManageRoles), handlers.CreateRole)
+		api.PUT("/teams/:team_id/roles/order", middleware.RequirePermission(models.PermissionManageRoles), handlers.ReorderRoles)
+		api.PUT("/teams/:team_id/roles/:role_id", middleware.RequirePermission(models.PermissionManageRoles), handlers.UpdateRole)
+		api.DELETE("/teams/:team_id/roles/:role_id", middleware.RequirePermission(models.PermissionManageRoles), handlers.DeleteRole)
+		api.PUT("/teams/:team_id/roles/:role_id/position", middleware.RequirePermission(models.PermissionManageRoles), handlers.SetRolePosition)
+		api.POST("/teams/:team_id/roles/:role_id/permissions", middleware.RequirePermission(models.PermissionManageRoles), handlers.AddRolePermissions)
+		api.DELETE("/teams/:team_id/roles/:role_id/permissions/:permission", middleware.RequirePermission(models.PermissionManageRoles), handlers.RemoveRolePermission)
+		api.POST("/teams/:team_id/users/:user_id/roles/:role_id", middleware.RequirePermission(models.PermissionManageRoles), handlers.AssignRole)
+		api.DELETE("/teams/:team_id/users/:user_id/roles/:role_id", middleware.RequirePermission(models.PermissionManageRoles), handlers.RemoveRole)
+		api.GET("/teams/:team_id/users/:user_id/roles", middleware.RequirePermission(models.PermissionViewMembers), handlers.GetUserRoles)
+		api.GET("/teams/:team_id/audit", middleware.RequirePermission(models.PermissionManageTeam), handlers.GetAuditEvents)
+		api.GET("/teams/:team_id/role-templates", middleware.RequirePermission(models.PermissionViewMembers), handlers.GetRoleTemplates)
+		api.PUT("/teams/:team_id/role-templates/:template_id", middleware.RequirePermission(models.PermissionManageRoles), handlers.UpdateRoleTemplate)
+
+		// Организации
+		api.POST("/organizations", handlers.CreateOrganization)
+		api.GET("/me/organizations", handlers.GetMyOrganizations)
+		api.GET("/organizations/:org_id", handlers.GetOrganization)
+		api.POST("/organizations/:org_id/teams", handlers.AddOrganizationTeam)
+		api.DELETE("/organizations/:org_id/teams/:team_id", handlers.RemoveOrganizationTeam)
+		api.PUT("/organizations/:org_id/members/:user_id", handlers.SetOrganizationMember)
+		api.DELETE("/organizations/:org_id/members/:user_id", handlers.RemoveOrganizationMember)
+		api.GET("/organizations/:org_id/stats", handlers.GetOrganizationStats)
 
 		// Valorant routes
-		api.POST("/users/:telegram_id/valorant", handlers.AddValorantPlayer)
-		api.GET("/users/:telegram_id/valorant", handlers.GetValorantPlayer)
-		api.GET("/teams/:team_id/valorant", handlers.GetTeamValorantPlayers)
-		api.POST("/users/:telegram_id/valorant/sync", handlers.SyncPlayerData)
-		api.GET("/users/:telegram_id/valorant/stats", handlers.GetPlayerStats)
-		api.GET("/teams/:team_id/valorant/stats", handlers.GetTeamStats)
+		api.POST("/me/valorant", handlers.AddValorantPlayer)
+		api.GET("/me/valorant", handlers.GetValorantPlayer)
+		api.GET("/teams/:team_id/valorant", middleware.RequirePermission(models.PermissionViewMembers), handlers.GetTeamValorantPlayers)
+		api.POST("/me/valorant/sync", handlers.SyncPlayerData)
+		api.GET("/me/valorant/stats", handlers.GetPlayerStats)
+		api.GET("/teams/:team_id/valorant/stats", middleware.RequirePermission(models.PermissionViewMembers), handlers.GetTeamStats)
+
+		// Администрирование
+		admin := api.Group("/admin", middleware.RequireAdmin(cfg.AdminTelegramIDs))
+		admin.GET("/valorant/cache", handlers.GetValorantCacheStats)
+		admin.GET("/valorant/sync", handlers.GetSyncStatus)
+		admin.POST("/valorant/sync", handlers.ForceSyncAll)
+		admin.POST("/valorant/players/:player_id/sync", handlers.ForceSyncPlayer)
 	}
 
 	// Webhook for Telegram bot (only if using webhook)
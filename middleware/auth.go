@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"valorant-app/models"
+	"valorant-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userContextKey ключ аутентифицированного пользователя в gin.Context
+const userContextKey = "user"
+
+// initDataHeader альтернативный заголовок с initData, если Authorization занят
+const initDataHeader = "X-Telegram-Init-Data"
+
+// TelegramUser пользователь из initData Telegram Mini App
+type TelegramUser struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	LanguageCode string `json:"language_code"`
+}
+
+// TelegramAuth проверяет подпись initData Telegram Mini App и кладет
+// пользователя в контекст. initData передается в заголовке
+// "Authorization: tma <initData>" или в X-Telegram-Init-Data.
+func TelegramAuth(botToken string, maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		initData := extractInitData(c.Request)
+		if initData == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing Telegram init data"})
+			return
+		}
+
+		tgUser, err := ValidateInitData(initData, botToken, maxAge, time.Now())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := services.RegisterUser(tgUser.ID, tgUser.Username, tgUser.FirstName, tgUser.LastName, tgUser.LanguageCode)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+			return
+		}
+		user.Origin = models.RequestOrigin{Source: models.AuditSourceAPI, RequestID: CurrentRequestID(c)}
+
+		c.Set(userContextKey, user)
+		c.Next()
+	}
+}
+
+// CurrentUser возвращает пользователя, аутентифицированного TelegramAuth
+func CurrentUser(c *gin.Context) *models.User {
+	value, ok := c.Get(userContextKey)
+	if !ok {
+		return nil
+	}
+	user, _ := value.(*models.User)
+	return user
+}
+
+// ValidateInitData проверяет HMAC-подпись initData и срок действия auth_date.
+// Алгоритм описан в https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+func ValidateInitData(initData, botToken string, maxAge time.Duration, now time.Time) (*TelegramUser, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, errors.New("Malformed init data")
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, errors.New("Init data is not signed")
+	}
+
+	// Строка для проверки: все поля кроме hash, отсортированные по ключу, через перевод строки
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key == "hash" {
+			continue
+		}
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+	dataCheckString := strings.Join(pairs, "\n")
+
+	secret := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	expected := hex.EncodeToString(hmacSHA256(secret, []byte(dataCheckString)))
+	if !hmac.Equal([]byte(expected), []byte(hash)) {
+		return nil, errors.New("Invalid init data signature")
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, errors.New("Invalid auth_date")
+	}
+	age := now.Sub(time.Unix(authDate, 0))
+	if age > maxAge || age < -time.Minute {
+		return nil, errors.New("Init data expired")
+	}
+
+	var user TelegramUser
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, errors.New("Init data has no user")
+	}
+
+	return &user, nil
+}
+
+func extractInitData(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "tma ") {
+		return strings.TrimPrefix(auth, "tma ")
+	}
+	return r.Header.Get(initDataHeader)
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
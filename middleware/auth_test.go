@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:TEST-token"
+
+// signInitData подписывает поля так же, как Telegram подписывает initData Mini App
+func signInitData(t *testing.T, botToken string, values url.Values) url.Values {
+	t.Helper()
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	secret := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	signed := url.Values{}
+	for key := range values {
+		signed.Set(key, values.Get(key))
+	}
+	signed.Set("hash", hex.EncodeToString(hmacSHA256(secret, []byte(strings.Join(pairs, "\n")))))
+	return signed
+}
+
+func TestValidateInitData(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	maxAge := 24 * time.Hour
+	user := `{"id":42,"username":"aurora","first_name":"Aurora","language_code":"ru"}`
+
+	fields := func(authDate time.Time) url.Values {
+		return url.Values{
+			"auth_date": {strconv.FormatInt(authDate.Unix(), 10)},
+			"query_id":  {"AAHdF6IQAAAAAN0XohDhrOrc"},
+			"user":      {user},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		initData func() string
+		wantErr  string
+	}{
+		{
+			name: "valid signature",
+			initData: func() string {
+				return signInitData(t, testBotToken, fields(now.Add(-time.Hour))).Encode()
+			},
+		},
+		{
+			name: "tampered field",
+			initData: func() string {
+				values := signInitData(t, testBotToken, fields(now.Add(-time.Hour)))
+				values.Set("user", `{"id":1,"username":"admin"}`)
+				return values.Encode()
+			},
+			wantErr: "Invalid init data signature",
+		},
+		{
+			name: "signed with another token",
+			initData: func() string {
+				return signInitData(t, "654321:OTHER-token", fields(now.Add(-time.Hour))).Encode()
+			},
+			wantErr: "Invalid init data signature",
+		},
+		{
+			name: "missing hash",
+			initData: func() string {
+				values := signInitData(t, testBotToken, fields(now.Add(-time.Hour)))
+				values.Del("hash")
+				return values.Encode()
+			},
+			wantErr: "Init data is not signed",
+		},
+		{
+			name: "stale auth_date",
+			initData: func() string {
+				return signInitData(t, testBotToken, fields(now.Add(-maxAge-time.Second))).Encode()
+			},
+			wantErr: "Init data expired",
+		},
+		{
+			name: "future auth_date",
+			initData: func() string {
+				return signInitData(t, testBotToken, fields(now.Add(2*time.Minute))).Encode()
+			},
+			wantErr: "Init data expired",
+		},
+		{
+			name: "small clock skew",
+			initData: func() string {
+				return signInitData(t, testBotToken, fields(now.Add(30*time.Second))).Encode()
+			},
+		},
+		{
+			name: "no user",
+			initData: func() string {
+				values := fields(now.Add(-time.Hour))
+				values.Del("user")
+				return signInitData(t, testBotToken, values).Encode()
+			},
+			wantErr: "Init data has no user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateInitData(tt.initData(), testBotToken, maxAge, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.ID != 42 || got.Username != "aurora" || got.LanguageCode != "ru" {
+				t.Errorf("user = %+v", got)
+			}
+		})
+	}
+}
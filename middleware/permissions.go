@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// teamContextKey ключ команды из :team_id в gin.Context
+const teamContextKey = "team"
+
+// RequirePermission пропускает запрос, только если у текущего пользователя есть
+// право permission в команде из параметра :team_id. Владелец команды имеет все права.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+
+		teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+			return
+		}
+
+		var team models.Team
+		result := database.DB.First(&team, teamID)
+		if result.Error != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+			return
+		}
+
+		if !utils.HasTeamPermission(user.ID, team.ID, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "Missing permission: " + permission,
+				"code":       "permission_denied",
+				"permission": permission,
+				"team_id":    team.ID,
+			})
+			return
+		}
+
+		c.Set(teamContextKey, &team)
+		c.Next()
+	}
+}
+
+// RequireAdmin пропускает запрос, только если Telegram ID текущего пользователя есть в adminIDs
+func RequireAdmin(adminIDs []int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+
+		for _, id := range adminIDs {
+			if user.TelegramID == id {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required", "code": "admin_required"})
+	}
+}
+
+// CurrentTeam возвращает команду, проверенную RequirePermission
+func CurrentTeam(c *gin.Context) *models.Team {
+	value, ok := c.Get(teamContextKey)
+	if !ok {
+		return nil
+	}
+	team, _ := value.(*models.Team)
+	return team
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader заголовок с ID запроса; принимается от клиента и возвращается в ответе
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDContextKey ключ ID запроса в gin.Context
+const requestIDContextKey = "request_id"
+
+// RequestID присваивает запросу ID для журнала и логов
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = newRequestID()
+		}
+
+		c.Set(requestIDContextKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// CurrentRequestID возвращает ID запроса, присвоенный RequestID
+func CurrentRequestID(c *gin.Context) string {
+	return c.GetString(requestIDContextKey)
+}
+
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// AuditEvent запись журнала изменений в команде
+type AuditEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TeamID     uint      `json:"team_id" gorm:"not null;index"`
+	ActorID    uint      `json:"actor_id" gorm:"not null;index"` // Кто выполнил действие
+	Actor      *User     `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	Action     string    `json:"action" gorm:"not null;index"` // Действие, например role.updated
+	TargetType string    `json:"target_type"`                  // Тип объекта: role, user
+	TargetID   uint      `json:"target_id"`
+	Before     string    `json:"before" gorm:"type:text"` // Состояние до изменения (JSON)
+	After      string    `json:"after" gorm:"type:text"`  // Состояние после изменения (JSON)
+	Source     string    `json:"source"`                  // Откуда пришел запрос: api или bot
+	RequestID  string    `json:"request_id" gorm:"index"` // ID запроса для связи с логами
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// RequestOrigin источник запроса, от имени которого пользователь выполняет действие
+type RequestOrigin struct {
+	Source    string
+	RequestID string
+}
+
+// Источники событий журнала
+const (
+	AuditSourceAPI = "api"
+	AuditSourceBot = "bot"
+)
+
+// Действия журнала
+const (
+	AuditTeamCreated          = "team.created"
+	AuditTeamDisbanded        = "team.disbanded"
+	AuditTeamUpdated          = "team.updated"
+	AuditOwnershipTransferred = "team.ownership_transferred"
+	AuditTeamOrganization     = "team.organization_changed"
+	AuditMemberJoined         = "member.joined"
+	AuditMemberLeft           = "member.left"
+	AuditMemberKicked         = "member.kicked"
+	AuditMemberRosterUpdated  = "member.roster_updated"
+	AuditTeamRosterLimit      = "team.roster_limit_changed"
+	AuditInvitationCreated    = "invitation.created"
+	AuditInvitationRevoked    = "invitation.revoked"
+	AuditJoinRequestApproved  = "join_request.approved"
+	AuditJoinRequestRejected  = "join_request.rejected"
+	AuditJoinRequestWithdrawn = "join_request.withdrawn"
+	AuditRoleTemplateUpdated  = "role_template.updated"
+
+	AuditRoleCreated           = "role.created"
+	AuditRoleUpdated           = "role.updated"
+	AuditRoleDeleted           = "role.deleted"
+	AuditRoleMoved             = "role.moved"
+	AuditRolePermissionAdded   = "role.permission_added"
+	AuditRolePermissionRemoved = "role.permission_removed"
+	AuditRoleAssigned          = "role.assigned"
+	AuditRoleUnassigned        = "role.unassigned"
+)
+
+// Типы объектов журнала
+const (
+	AuditTargetTeam         = "team"
+	AuditTargetUser         = "user"
+	AuditTargetRole         = "role"
+	AuditTargetRoleTemplate = "role_template"
+	AuditTargetInvitation   = "invitation"
+	AuditTargetJoinRequest  = "join_request"
+)
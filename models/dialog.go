@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DialogState состояние многошагового диалога бота в чате.
+// Хранится в БД, чтобы диалог переживал перезапуск и работал одинаково в режимах polling и webhook.
+type DialogState struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	ChatID         int64     `json:"chat_id" gorm:"uniqueIndex;not null"` // Чат, в котором идет диалог
+	TelegramUserID int64     `json:"telegram_user_id" gorm:"not null"`    // Пользователь, который ведет диалог
+	Dialog         string    `json:"dialog" gorm:"not null"`              // Название диалога
+	Step           string    `json:"step" gorm:"not null"`                // Текущий шаг
+	Data           string    `json:"data" gorm:"type:text"`               // Введенные значения (JSON)
+	ExpiresAt      time.Time `json:"expires_at" gorm:"index"`             // Время истечения диалога
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
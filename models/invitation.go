@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Invitation приглашение в команду по ссылке t.me/<bot>?start=<token>
+type Invitation struct {
+	ID           uint                   `json:"id" gorm:"primaryKey"`
+	Token        string                 `json:"token" gorm:"uniqueIndex;not null"` // Код для deep link
+	TeamID       uint                   `json:"team_id" gorm:"not null;index"`
+	Team         Team                   `json:"-" gorm:"foreignKey:TeamID"`
+	InviterID    uint                   `json:"inviter_id" gorm:"not null"` // Кто создал приглашение
+	Inviter      User                   `json:"-" gorm:"foreignKey:InviterID"`
+	TargetUserID *uint                  `json:"target_user_id"` // Если задан, приглашение только для этого пользователя
+	TargetUser   *User                  `json:"-" gorm:"foreignKey:TargetUserID"`
+	MaxUses      int                    `json:"max_uses"` // 0 — без ограничения
+	Uses         int                    `json:"uses"`
+	ExpiresAt    time.Time              `json:"expires_at"`
+	RevokedAt    *time.Time             `json:"revoked_at"`
+	Link         string                 `json:"link,omitempty" gorm:"-"`
+	Redemptions  []InvitationRedemption `json:"redemptions,omitempty" gorm:"foreignKey:InvitationID"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt         `json:"deleted_at" gorm:"index"`
+}
+
+// InvitationRedemption запись об использовании приглашения
+type InvitationRedemption struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	InvitationID uint      `json:"invitation_id" gorm:"not null;index"`
+	UserID       uint      `json:"user_id" gorm:"not null"`
+	User         User      `json:"user" gorm:"foreignKey:UserID"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JoinRequest заявка пользователя на вступление в команду
+type JoinRequest struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	TeamID     uint           `json:"team_id" gorm:"not null;index"`
+	Team       *Team          `json:"team,omitempty" gorm:"foreignKey:TeamID"`
+	UserID     uint           `json:"user_id" gorm:"not null;index"`
+	User       *User          `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Status     string         `json:"status" gorm:"not null;index"` // Статус заявки
+	Message    string         `json:"message"`                      // Сообщение от кандидата
+	ReviewerID *uint          `json:"reviewer_id"`                  // Кто рассмотрел заявку
+	ReviewedAt *time.Time     `json:"reviewed_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// Статусы заявок на вступление
+const (
+	JoinRequestPending   = "pending"   // Ожидает рассмотрения
+	JoinRequestApproved  = "approved"  // Одобрена
+	JoinRequestRejected  = "rejected"  // Отклонена
+	JoinRequestWithdrawn = "withdrawn" // Отозвана кандидатом
+)
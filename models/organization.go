@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Organization клуб, которому принадлежат несколько команд (основной состав, академия и т.д.)
+type Organization struct {
+	ID          uint                 `json:"id" gorm:"primaryKey"`
+	Name        string               `json:"name" gorm:"not null"`
+	Description string               `json:"description"`
+	CreatedBy   uint                 `json:"created_by"` // Владелец организации
+	Teams       []Team               `json:"teams" gorm:"foreignKey:OrganizationID"`
+	Members     []OrganizationMember `json:"members,omitempty" gorm:"foreignKey:OrganizationID"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt       `json:"deleted_at" gorm:"index"`
+}
+
+// OrganizationMember роль пользователя на уровне организации.
+// Права роли действуют во всех командах организации.
+type OrganizationMember struct {
+	OrganizationID uint      `json:"organization_id" gorm:"primaryKey"`
+	UserID         uint      `json:"user_id" gorm:"primaryKey;index"`
+	User           *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Role           string    `json:"role" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Роли организации
+const (
+	OrgRoleOwner     = "owner"      // Владелец организации, все права во всех командах
+	OrgRoleManager   = "manager"    // Менеджер
+	OrgRoleHeadCoach = "head_coach" // Главный тренер
+)
+
+// orgRolePermissions права, которые роль организации дает в каждой команде организации
+var orgRolePermissions = map[string][]string{
+	OrgRoleManager: {
+		PermissionManageTeam,
+		PermissionManageRoles,
+		PermissionKickMembers,
+		PermissionInviteMembers,
+		PermissionViewMembers,
+		PermissionScheduleScrims,
+		PermissionManageTournaments,
+		PermissionPostAnnouncements,
+	},
+	OrgRoleHeadCoach: {
+		PermissionViewMembers,
+		PermissionScheduleScrims,
+		PermissionEditStrategies,
+		PermissionSyncStats,
+	},
+}
+
+// OrgRolePermissions возвращает права роли организации в ее командах.
+// Владелец организации получает все зарегистрированные права.
+func OrgRolePermissions(role string) []string {
+	if role == OrgRoleOwner {
+		return RegisteredPermissionNames()
+	}
+	return orgRolePermissions[role]
+}
+
+// IsAssignableOrgRole проверяет, что роль можно назначить участнику организации
+func IsAssignableOrgRole(role string) bool {
+	_, ok := orgRolePermissions[role]
+	return ok
+}
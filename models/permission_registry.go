@@ -0,0 +1,126 @@
+package models
+
+import "strings"
+
+// PermissionDefinition описание права в каталоге: группа и описания по языкам
+type PermissionDefinition struct {
+	Name         string
+	Group        string
+	Descriptions map[string]string
+}
+
+// PermissionGroupDefinition группа прав каталога с названиями по языкам
+type PermissionGroupDefinition struct {
+	Name   string
+	Titles map[string]string
+}
+
+// DefaultPermissionLanguage язык описаний прав, сохраняемых в таблицу permissions
+const DefaultPermissionLanguage = "ru"
+
+var (
+	permissionGroups []PermissionGroupDefinition
+	permissionIndex  = map[string]int{}
+	permissionList   []PermissionDefinition
+)
+
+// RegisterPermissionGroup добавляет группу прав в каталог; повторная регистрация заменяет названия
+func RegisterPermissionGroup(group PermissionGroupDefinition) {
+	for i := range permissionGroups {
+		if permissionGroups[i].Name == group.Name {
+			permissionGroups[i] = group
+			return
+		}
+	}
+	permissionGroups = append(permissionGroups, group)
+}
+
+// RegisterPermission добавляет право в каталог. Модули регистрируют свои права при запуске,
+// а сидер синхронизирует каталог с таблицей permissions.
+func RegisterPermission(definition PermissionDefinition) {
+	if i, ok := permissionIndex[definition.Name]; ok {
+		permissionList[i] = definition
+		return
+	}
+	permissionIndex[definition.Name] = len(permissionList)
+	permissionList = append(permissionList, definition)
+}
+
+// RegisteredPermissions возвращает все права каталога в порядке регистрации
+func RegisteredPermissions() []PermissionDefinition {
+	return append([]PermissionDefinition(nil), permissionList...)
+}
+
+// RegisteredPermissionGroups возвращает группы прав в порядке регистрации
+func RegisteredPermissionGroups() []PermissionGroupDefinition {
+	return append([]PermissionGroupDefinition(nil), permissionGroups...)
+}
+
+// RegisteredPermissionNames возвращает названия всех прав каталога
+func RegisteredPermissionNames() []string {
+	names := make([]string, 0, len(permissionList))
+	for _, definition := range permissionList {
+		names = append(names, definition.Name)
+	}
+	return names
+}
+
+// Localized возвращает перевод для языка lang с откатом на DefaultPermissionLanguage
+func Localized(values map[string]string, lang string) string {
+	if value, ok := values[strings.ToLower(lang)]; ok {
+		return value
+	}
+	return values[DefaultPermissionLanguage]
+}
+
+// Группы прав
+const (
+	PermissionGroupTeam          = "team"
+	PermissionGroupMembers       = "members"
+	PermissionGroupScrims        = "scrims"
+	PermissionGroupStrategies    = "strategies"
+	PermissionGroupStats         = "stats"
+	PermissionGroupTournaments   = "tournaments"
+	PermissionGroupAnnouncements = "announcements"
+)
+
+// Права функций команды
+const (
+	PermissionScheduleScrims    = "schedule_scrims"    // Планирование праков
+	PermissionEditStrategies    = "edit_strategies"    // Редактирование стратегий
+	PermissionSyncStats         = "sync_stats"         // Синхронизация статистики команды
+	PermissionManageTournaments = "manage_tournaments" // Управление турнирами
+	PermissionPostAnnouncements = "post_announcements" // Публикация объявлений
+)
+
+func init() {
+	groups := []PermissionGroupDefinition{
+		{PermissionGroupTeam, map[string]string{"ru": "Команда", "en": "Team"}},
+		{PermissionGroupMembers, map[string]string{"ru": "Участники", "en": "Members"}},
+		{PermissionGroupScrims, map[string]string{"ru": "Праки", "en": "Scrims"}},
+		{PermissionGroupStrategies, map[string]string{"ru": "Стратегии", "en": "Strategies"}},
+		{PermissionGroupStats, map[string]string{"ru": "Статистика", "en": "Stats"}},
+		{PermissionGroupTournaments, map[string]string{"ru": "Турниры", "en": "Tournaments"}},
+		{PermissionGroupAnnouncements, map[string]string{"ru": "Объявления", "en": "Announcements"}},
+	}
+	for _, group := range groups {
+		RegisterPermissionGroup(group)
+	}
+
+	permissions := []PermissionDefinition{
+		{PermissionManageTeam, PermissionGroupTeam, map[string]string{"ru": "Управление командой", "en": "Manage the team"}},
+		{PermissionManageRoles, PermissionGroupTeam, map[string]string{"ru": "Управление ролями", "en": "Manage roles"}},
+		{PermissionKickMembers, PermissionGroupMembers, map[string]string{"ru": "Исключение участников", "en": "Kick members"}},
+		{PermissionInviteMembers, PermissionGroupMembers, map[string]string{"ru": "Приглашение участников", "en": "Invite members"}},
+		{PermissionViewMembers, PermissionGroupMembers, map[string]string{"ru": "Просмотр участников", "en": "View members"}},
+		{PermissionEditProfile, PermissionGroupMembers, map[string]string{"ru": "Редактирование профиля", "en": "Edit profile"}},
+		{PermissionScheduleScrims, PermissionGroupScrims, map[string]string{"ru": "Планирование праков", "en": "Schedule scrims"}},
+		{PermissionEditStrategies, PermissionGroupStrategies, map[string]string{"ru": "Редактирование стратегий", "en": "Edit strategies"}},
+		{PermissionSyncStats, PermissionGroupStats, map[string]string{"ru": "Синхронизация статистики команды", "en": "Sync team stats"}},
+		{PermissionManageTournaments, PermissionGroupTournaments, map[string]string{"ru": "Управление турнирами", "en": "Manage tournaments"}},
+		{PermissionPostAnnouncements, PermissionGroupAnnouncements, map[string]string{"ru": "Публикация объявлений", "en": "Post announcements"}},
+	}
+	for _, permission := range permissions {
+		RegisterPermission(permission)
+	}
+}
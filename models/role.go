@@ -11,6 +11,7 @@ type Role struct {
 	Name        string         `json:"name" gorm:"not null"`
 	Description string         `json:"description"`
 	TeamID      uint           `json:"team_id" gorm:"not null"`
+	Position    int            `json:"position" gorm:"not null;default:0"` // Чем больше, тем выше роль в иерархии
 	Team        Team           `json:"team" gorm:"foreignKey:TeamID"`
 	Permissions []Permission   `json:"permissions" gorm:"many2many:role_permissions;"`
 	Users       []User         `json:"users" gorm:"many2many:user_roles;"`
@@ -22,6 +23,7 @@ type Role struct {
 type Permission struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	Name        string         `json:"name" gorm:"unique;not null"`
+	Group       string         `json:"group" gorm:"column:permission_group;index"` // Группа в каталоге прав
 	Description string         `json:"description"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoleTemplate шаблон роли, из которого создается набор ролей команды.
+// Шаблоны без TeamID образуют общий каталог; при создании команды каталог
+// копируется в шаблоны команды, которые владелец может редактировать.
+type RoleTemplate struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	TeamID      *uint          `json:"team_id" gorm:"index"`
+	Name        string         `json:"name" gorm:"not null"`
+	Title       string         `json:"title"`       // Отображаемое название
+	Description string         `json:"description"` // Описание роли
+	Position    int            `json:"position"`    // Позиция создаваемой роли в иерархии
+	Permissions []Permission   `json:"permissions" gorm:"many2many:role_template_permissions;"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
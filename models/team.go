@@ -7,12 +7,16 @@ import (
 )
 
 type Team struct {
-	ID          uint   `json:"id" gorm:"primaryKey"`
-	Name        string `json:"name" gorm:"not null"`
-	Description string `json:"description"`
-	CreatedBy   uint   `json:"created_by"`
+	ID             uint   `json:"id" gorm:"primaryKey"`
+	Name           string `json:"name" gorm:"not null"`
+	Description    string `json:"description"`
+	CreatedBy      uint   `json:"created_by"`
+	OrganizationID *uint  `json:"organization_id" gorm:"index"`             // Организация, которой принадлежит команда
+	RosterLimit    int    `json:"roster_limit" gorm:"not null;default:0"`   // Максимум участников, 0 — без ограничения
+	Region         string `json:"region" gorm:"index"`                      // Регион команды (eu, na, ap, ...)
+	Recruiting     bool   `json:"recruiting" gorm:"not null;default:false"` // Команда ищет игроков
 	// Creator     User           `json:"creator" gorm:"foreignKey:CreatedBy"`
-	Members   []User         `json:"members" gorm:"foreignKey:TeamID"`
+	Members   []User         `json:"members,omitempty" gorm:"many2many:team_memberships;"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
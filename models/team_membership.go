@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// TeamMembership членство пользователя в команде. Пользователь может состоять
+// в нескольких командах (основной состав, академия, турнирный состав).
+// Запись существует, пока пользователь в команде; история — в журнале аудита.
+type TeamMembership struct {
+	TeamID     uint      `json:"team_id" gorm:"primaryKey"`
+	Team       *Team     `json:"team,omitempty" gorm:"foreignKey:TeamID"`
+	UserID     uint      `json:"user_id" gorm:"primaryKey;index"`
+	User       *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Status     string    `json:"status" gorm:"not null;default:active"` // Статус участника в составе
+	RosterSlot string    `json:"roster_slot"`                           // Позиция в составе
+	InGameRole string    `json:"in_game_role"`                          // Предпочитаемая игровая роль
+	MainAgents []string  `json:"main_agents" gorm:"serializer:json"`    // Основные агенты
+	JoinedAt   time.Time `json:"joined_at" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Статусы членства в команде
+const (
+	MembershipActive   = "active"   // Действующий участник
+	MembershipTrial    = "trial"    // Испытательный срок
+	MembershipInactive = "inactive" // Временно не играет
+)
+
+// MembershipStatuses допустимые статусы членства
+var MembershipStatuses = []string{MembershipActive, MembershipTrial, MembershipInactive}
+
+// Позиции в составе. Роли команды (RoleOwner и др.) означают права управления,
+// а позиция — место участника в составе.
+const (
+	RosterStarter = "starter" // Основной состав
+	RosterSub     = "sub"     // Запасной
+	RosterCoach   = "coach"   // Тренер
+	RosterAnalyst = "analyst" // Аналитик
+	RosterManager = "manager" // Менеджер
+)
+
+// RosterSlots позиции в составе в порядке отображения
+var RosterSlots = []string{RosterStarter, RosterSub, RosterCoach, RosterAnalyst, RosterManager}
+
+// Предпочитаемые игровые роли
+const (
+	InGameRoleDuelist    = "duelist"
+	InGameRoleInitiator  = "initiator"
+	InGameRoleController = "controller"
+	InGameRoleSentinel   = "sentinel"
+	InGameRoleFlex       = "flex"
+)
+
+// InGameRoles игровые роли в порядке отображения
+var InGameRoles = []string{InGameRoleDuelist, InGameRoleInitiator, InGameRoleController, InGameRoleSentinel, InGameRoleFlex}
+
+// MaxStarters размер основного состава
+const MaxStarters = 5
+
+// MaxMainAgents сколько основных агентов может указать игрок
+const MaxMainAgents = 3
+
+// IsPlayerSlot проверяет, что позиция игровая (основной состав или запас)
+func IsPlayerSlot(slot string) bool {
+	return slot == RosterStarter || slot == RosterSub
+}
+
+// contains проверяет наличие значения в списке
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRosterSlot проверяет, что позиция в составе известна
+func IsRosterSlot(slot string) bool {
+	return contains(RosterSlots, slot)
+}
+
+// IsInGameRole проверяет, что игровая роль известна
+func IsInGameRole(role string) bool {
+	return contains(InGameRoles, role)
+}
+
+// IsMembershipStatus проверяет, что статус членства известен
+func IsMembershipStatus(status string) bool {
+	return contains(MembershipStatuses, status)
+}
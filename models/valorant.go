@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,17 +12,25 @@ type ValorantPlayer struct {
 	ID         uint           `json:"id" gorm:"primaryKey"`
 	UserID     uint           `json:"user_id"`
 	User       User           `json:"user" gorm:"foreignKey:UserID"`
-	GameName   string         `json:"game_name"`   // Игровое имя
-	Tag        string         `json:"tag"`         // Тег игрока
-	Region     string         `json:"region"`      // Регион (eu, na, ap, etc.)
-	Rank       string         `json:"rank"`        // Текущий ранг
-	RankRating int            `json:"rank_rating"` // Рейтинг ранга
-	PeakRank   string         `json:"peak_rank"`   // Пиковый ранг
-	PeakRating int            `json:"peak_rating"` // Пиковый рейтинг
-	Level      int            `json:"level"`       // Уровень аккаунта
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	Puuid      string         `json:"puuid" gorm:"index"` // PUUID аккаунта Riot
+	GameName   string         `json:"game_name"`          // Игровое имя
+	Tag        string         `json:"tag"`                // Тег игрока
+	Region     string         `json:"region"`             // Регион (eu, na, ap, etc.)
+	Rank       string         `json:"rank"`               // Текущий ранг
+	RankRating int            `json:"rank_rating"`        // Рейтинг ранга
+	PeakRank   string         `json:"peak_rank"`          // Пиковый ранг
+	PeakRating int            `json:"peak_rating"`        // Пиковый рейтинг
+	Level      int            `json:"level"`              // Уровень аккаунта
+	Stats      *ValorantStats `json:"stats,omitempty" gorm:"foreignKey:PlayerID"`
+
+	LastSyncedAt *time.Time `json:"last_synced_at" gorm:"index"` // Последняя успешная синхронизация
+	LastError    string     `json:"last_error"`                  // Ошибка последней неудачной синхронизации
+	SyncFailures int        `json:"sync_failures"`               // Неудачных синхронизаций подряд
+	NextSyncAt   *time.Time `json:"next_sync_at"`                // Раньше этого времени планировщик аккаунт не синхронизирует
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 // ValorantMatch представляет матч в Valorant
@@ -42,20 +51,31 @@ type ValorantMatch struct {
 	DeletedAt gorm.DeletedAt        `json:"deleted_at" gorm:"index"`
 }
 
+// TeamMatchArchive матч, учтенный в статистике команды на момент ее роспуска.
+// Архив ведется по командам, потому что один матч может учитываться для нескольких команд.
+type TeamMatchArchive struct {
+	TeamID     uint      `json:"team_id" gorm:"primaryKey"`
+	MatchID    uint      `json:"match_id" gorm:"primaryKey"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
 // ValorantPlayerMatch связывает игрока с матчем
 type ValorantPlayerMatch struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
-	MatchID     uint           `json:"match_id"`
+	MatchID     uint           `json:"match_id" gorm:"uniqueIndex:idx_player_match"`
 	Match       ValorantMatch  `json:"match" gorm:"foreignKey:MatchID"`
-	PlayerID    uint           `json:"player_id"`
+	PlayerID    uint           `json:"player_id" gorm:"uniqueIndex:idx_player_match"`
 	Player      ValorantPlayer `json:"player" gorm:"foreignKey:PlayerID"`
+	Result      string         `json:"result"`       // Результат для игрока (win/loss)
 	Agent       string         `json:"agent"`        // Агент
 	Kills       int            `json:"kills"`        // Убийства
 	Deaths      int            `json:"deaths"`       // Смерти
 	Assists     int            `json:"assists"`      // Помощи
 	Score       int            `json:"score"`        // Очки
 	Damage      int            `json:"damage"`       // Урон
-	Headshots   int            `json:"headshots"`    // Хедшоты
+	Headshots   int            `json:"headshots"`    // Попадания в голову
+	Bodyshots   int            `json:"bodyshots"`    // Попадания в тело
+	Legshots    int            `json:"legshots"`     // Попадания в ноги
 	FirstKills  int            `json:"first_kills"`  // Первые убийства
 	FirstDeaths int            `json:"first_deaths"` // Первые смерти
 	CreatedAt   time.Time      `json:"created_at"`
@@ -66,7 +86,7 @@ type ValorantPlayerMatch struct {
 // ValorantStats статистика игрока
 type ValorantStats struct {
 	ID             uint           `json:"id" gorm:"primaryKey"`
-	PlayerID       uint           `json:"player_id"`
+	PlayerID       uint           `json:"player_id" gorm:"uniqueIndex"`
 	Player         ValorantPlayer `json:"player" gorm:"foreignKey:PlayerID"`
 	TotalMatches   int            `json:"total_matches"`   // Всего матчей
 	Wins           int            `json:"wins"`            // Победы
@@ -76,8 +96,56 @@ type ValorantStats struct {
 	AverageKills   float64        `json:"average_kills"`   // Средние убийства
 	AverageDeaths  float64        `json:"average_deaths"`  // Средние смерти
 	AverageAssists float64        `json:"average_assists"` // Средние помощи
-	HeadshotRate   float64        `json:"headshot_rate"`   // Процент хедшотов
+	HeadshotRate   float64        `json:"headshot_rate"`   // Процент попаданий в голову от всех попаданий
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
+
+// Результаты матча
+const (
+	MatchResultWin  = "win"
+	MatchResultLoss = "loss"
+)
+
+// ValorantRegions регионы Valorant, допустимые для аккаунтов и команд
+var ValorantRegions = []string{"eu", "na", "ap", "kr", "latam", "br"}
+
+// IsValorantRegion проверяет, что регион есть в ValorantRegions
+func IsValorantRegion(region string) bool {
+	return contains(ValorantRegions, region)
+}
+
+// RankTiers ранги Valorant в порядке возрастания
+var RankTiers = []string{
+	"Unranked",
+	"Iron 1", "Iron 2", "Iron 3",
+	"Bronze 1", "Bronze 2", "Bronze 3",
+	"Silver 1", "Silver 2", "Silver 3",
+	"Gold 1", "Gold 2", "Gold 3",
+	"Platinum 1", "Platinum 2", "Platinum 3",
+	"Diamond 1", "Diamond 2", "Diamond 3",
+	"Ascendant 1", "Ascendant 2", "Ascendant 3",
+	"Immortal 1", "Immortal 2", "Immortal 3",
+	"Radiant",
+}
+
+// RankTierIndex возвращает позицию ранга в RankTiers или -1, если ранг неизвестен
+func RankTierIndex(rank string) int {
+	for i, tier := range RankTiers {
+		if strings.EqualFold(tier, rank) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValorantMatchCache сырой JSON деталей матча от поставщика данных.
+// Матчи не меняются, поэтому ответ хранится бессрочно и переживает перезапуск без повторных запросов к API.
+type ValorantMatchCache struct {
+	Key          string    `json:"key" gorm:"primaryKey"`        // URL запроса к API
+	Body         string    `json:"-" gorm:"type:jsonb;not null"` // Тело ответа как есть
+	ETag         string    `json:"etag"`                         // Для условных запросов
+	LastModified string    `json:"last_modified"`                // Для условных запросов
+	CreatedAt    time.Time `json:"created_at"`
+}
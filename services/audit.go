@@ -0,0 +1,173 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+	"valorant-app/database"
+	"valorant-app/models"
+
+	"gorm.io/gorm"
+)
+
+// roleSnapshot состояние роли для журнала
+type roleSnapshot struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Position    int      `json:"position"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+func snapshotRole(role *models.Role) *roleSnapshot {
+	return &roleSnapshot{
+		Name:        role.Name,
+		Description: role.Description,
+		Position:    role.Position,
+		Permissions: permissionNames(role.Permissions),
+	}
+}
+
+// teamSnapshot состояние команды для журнала
+type teamSnapshot struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedBy   uint   `json:"created_by"`
+	RosterLimit int    `json:"roster_limit,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Recruiting  bool   `json:"recruiting,omitempty"`
+}
+
+func snapshotTeam(team *models.Team) *teamSnapshot {
+	return &teamSnapshot{
+		Name:        team.Name,
+		Description: team.Description,
+		CreatedBy:   team.CreatedBy,
+		RosterLimit: team.RosterLimit,
+		Region:      team.Region,
+		Recruiting:  team.Recruiting,
+	}
+}
+
+// membershipSnapshot состояние участника в составе для журнала
+type membershipSnapshot struct {
+	Status     string   `json:"status"`
+	RosterSlot string   `json:"roster_slot"`
+	InGameRole string   `json:"in_game_role,omitempty"`
+	MainAgents []string `json:"main_agents,omitempty"`
+}
+
+func snapshotMembership(membership *models.TeamMembership) *membershipSnapshot {
+	return &membershipSnapshot{
+		Status:     membership.Status,
+		RosterSlot: membership.RosterSlot,
+		InGameRole: membership.InGameRole,
+		MainAgents: membership.MainAgents,
+	}
+}
+
+// roleTemplateSnapshot состояние шаблона роли для журнала
+type roleTemplateSnapshot struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+func snapshotRoleTemplate(template *models.RoleTemplate) *roleTemplateSnapshot {
+	return &roleTemplateSnapshot{
+		Title:       template.Title,
+		Description: template.Description,
+		Permissions: permissionNames(template.Permissions),
+	}
+}
+
+// recordAudit записывает событие журнала в той же транзакции, что и само изменение.
+// before и after сохраняются как JSON; nil означает отсутствие состояния.
+func recordAudit(tx *gorm.DB, actor *models.User, teamID uint, action, targetType string, targetID uint, before, after interface{}) error {
+	event := models.AuditEvent{
+		TeamID:     teamID,
+		ActorID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     auditJSON(before),
+		After:      auditJSON(after),
+		Source:     actor.Origin.Source,
+		RequestID:  actor.Origin.RequestID,
+	}
+	return tx.Create(&event).Error
+}
+
+func auditJSON(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// AuditFilter фильтры и курсор журнала команды
+type AuditFilter struct {
+	Action     string
+	ActorID    uint
+	TargetType string
+	TargetID   uint
+	From       *time.Time
+	To         *time.Time
+	Cursor     uint // ID последней полученной записи; записи отдаются от новых к старым
+	Limit      int
+}
+
+// Ограничения размера страницы журнала
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 100
+)
+
+// ListAuditEvents возвращает страницу журнала команды и курсор следующей страницы (0 — страниц больше нет)
+func ListAuditEvents(teamID uint, filter AuditFilter) ([]models.AuditEvent, uint, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	if limit > MaxAuditLimit {
+		limit = MaxAuditLimit
+	}
+
+	query := database.DB.Preload("Actor").Where("team_id = ?", teamID)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Cursor != 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	var events []models.AuditEvent
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var next uint
+	if len(events) > limit {
+		events = events[:limit]
+		next = events[limit-1].ID
+	}
+	return events, next, nil
+}
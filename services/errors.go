@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Ошибки бизнес-логики, общие для REST API и бота
+var (
+	ErrUserNotFound    = errors.New("User not found")
+	ErrTeamNotFound    = errors.New("Team not found")
+	ErrRoleNotFound    = errors.New("Role not found in this team")
+	ErrUserNotInTeam   = errors.New("User not found in this team")
+	ErrAlreadyInTeam   = errors.New("User is already a member of this team")
+	ErrNotInTeam       = errors.New("User is not in a team")
+	ErrPlayerNotLinked = errors.New("Valorant account not linked")
+
+	ErrInvitationNotFound   = errors.New("Invitation not found")
+	ErrInvitationExpired    = errors.New("Invitation has expired")
+	ErrInvitationRevoked    = errors.New("Invitation has been revoked")
+	ErrInvitationUsedUp     = errors.New("Invitation has no uses left")
+	ErrInvitationNotForUser = errors.New("Invitation is intended for another user")
+
+	ErrJoinRequestNotFound   = errors.New("Join request not found")
+	ErrJoinRequestExists     = errors.New("Join request already pending")
+	ErrJoinRequestNotPending = errors.New("Join request has already been processed")
+
+	ErrRoleHierarchy = errors.New("Cannot manage a member with an equal or higher role")
+	ErrNotTeamOwner  = errors.New("Only the team owner can do this")
+	ErrAlreadyOwner  = errors.New("User is already the team owner")
+
+	ErrOwnerCannotLeave = errors.New("Team owner cannot leave; transfer ownership or disband the team")
+
+	ErrRoleTemplateNotFound = errors.New("Role template not found in this team")
+	ErrProtectedRole        = errors.New("The owner role cannot be modified")
+	ErrUnknownPermission    = errors.New("Unknown permission")
+
+	ErrRoleNameTaken        = errors.New("Role with this name already exists in the team")
+	ErrPermissionEscalation = errors.New("Cannot grant a permission you do not have")
+
+	ErrOrganizationNotFound       = errors.New("Organization not found")
+	ErrOrganizationAccess         = errors.New("Only the organization owner or manager can do this")
+	ErrOrganizationViewAccess     = errors.New("Only organization staff and members of its teams can view it")
+	ErrOrganizationMemberNotFound = errors.New("User has no role in this organization")
+	ErrUnknownOrgRole             = errors.New("Unknown organization role")
+	ErrTeamInOrganization         = errors.New("Team already belongs to an organization")
+	ErrTeamNotInOrganization      = errors.New("Team does not belong to this organization")
+
+	ErrTeamFull          = errors.New("Team roster is full")
+	ErrStartersFull      = errors.New("Starting lineup is already full")
+	ErrRosterLimitTooLow = errors.New("Roster limit is below the current number of members")
+	ErrInvalidRosterSlot = errors.New("Unknown roster slot")
+	ErrInvalidInGameRole = errors.New("Unknown in-game role")
+	ErrInvalidStatus     = errors.New("Unknown membership status")
+	ErrTooManyMainAgents = errors.New("Too many main agents")
+
+	ErrInvalidCursor = errors.New("Invalid cursor")
+	ErrInvalidRegion = errors.New("Unknown region")
+
+	ErrPlayerNotFound = errors.New("Valorant player not found")
+	ErrSyncInProgress = errors.New("Sync is already in progress for this account")
+)
+
+// Ошибки Valorant API; подробности ответа — в ValorantAPIError
+var (
+	ErrValorantNotFound     = errors.New("Valorant account or match not found")
+	ErrValorantRateLimited  = errors.New("Valorant API rate limit exceeded")
+	ErrValorantUnauthorized = errors.New("Valorant API rejected the API key")
+	ErrValorantUpstream     = errors.New("Valorant API is unavailable")
+)
+
+// ValorantAPIError неуспешный запрос к Valorant API: тип ошибки Kind (одна из ErrValorant*),
+// HTTP-статус (0 — ответа нет) и рекомендованная пауза перед повтором
+type ValorantAPIError struct {
+	Kind       error
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error // Сетевая ошибка, если ответа нет
+}
+
+func (e *ValorantAPIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s (status %d)", e.Kind, e.StatusCode)
+}
+
+// Is позволяет сравнивать ValorantAPIError с ее типом через errors.Is
+func (e *ValorantAPIError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *ValorantAPIError) Unwrap() error {
+	return e.Err
+}
+
+// ErrPermissionDenied базовая ошибка отсутствия права; конкретное право — в PermissionError
+var ErrPermissionDenied = errors.New("Permission denied")
+
+// PermissionError отсутствие права permission у пользователя в команде
+type PermissionError struct {
+	Permission string
+}
+
+func (e *PermissionError) Error() string {
+	return "Missing permission: " + e.Permission
+}
+
+// Is позволяет сравнивать PermissionError с ErrPermissionDenied через errors.Is
+func (e *PermissionError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// UnknownPermissionError неизвестное название права в запросе
+type UnknownPermissionError struct {
+	Name string
+}
+
+func (e *UnknownPermissionError) Error() string {
+	return "Unknown permission: " + e.Name
+}
+
+// Is позволяет сравнивать UnknownPermissionError с ErrUnknownPermission через errors.Is
+func (e *UnknownPermissionError) Is(target error) bool {
+	return target == ErrUnknownPermission
+}
+
+// EscalationError попытка выдать право permission, которого нет у самого пользователя
+type EscalationError struct {
+	Permission string
+}
+
+func (e *EscalationError) Error() string {
+	return "Cannot grant a permission you do not have: " + e.Permission
+}
+
+// Is позволяет сравнивать EscalationError с ErrPermissionEscalation через errors.Is
+func (e *EscalationError) Is(target error) bool {
+	return target == ErrPermissionEscalation
+}
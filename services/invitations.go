@@ -0,0 +1,206 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BotUsername имя бота для deep link приглашений, задается при запуске
+var BotUsername string
+
+// Ограничения срока действия приглашений
+const (
+	DefaultInvitationTTL = 24 * time.Hour
+	MaxInvitationTTL     = 30 * 24 * time.Hour
+)
+
+// InvitationOptions параметры нового приглашения
+type InvitationOptions struct {
+	TargetUserID *uint
+	MaxUses      int // 0 — без ограничения
+	TTL          time.Duration
+}
+
+// CreateInvitation создает приглашение в команду пользователя inviter
+func CreateInvitation(inviter *models.User, teamID uint, options InvitationOptions) (*models.Invitation, error) {
+	if options.TargetUserID != nil {
+		var target models.User
+		if err := database.DB.First(&target, *options.TargetUserID).Error; err != nil {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	ttl := options.TTL
+	if ttl <= 0 {
+		ttl = DefaultInvitationTTL
+	}
+	if ttl > MaxInvitationTTL {
+		ttl = MaxInvitationTTL
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := models.Invitation{
+		Token:        token,
+		TeamID:       teamID,
+		InviterID:    inviter.ID,
+		TargetUserID: options.TargetUserID,
+		MaxUses:      options.MaxUses,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, inviter, teamID, models.AuditInvitationCreated, models.AuditTargetInvitation, invitation.ID, nil,
+			map[string]interface{}{"target_user_id": invitation.TargetUserID, "max_uses": invitation.MaxUses, "expires_at": invitation.ExpiresAt})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.Link = InvitationLink(invitation.Token)
+	return &invitation, nil
+}
+
+// ListInvitations возвращает приглашения команды вместе с использованиями
+func ListInvitations(teamID uint) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	result := database.DB.Preload("Redemptions").Where("team_id = ?", teamID).Order("created_at DESC").Find(&invitations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for i := range invitations {
+		invitations[i].Link = InvitationLink(invitations[i].Token)
+	}
+	return invitations, nil
+}
+
+// FindInvitation находит приглашение по коду
+func FindInvitation(token string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	result := database.DB.Where("token = ?", token).First(&invitation)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &invitation, nil
+}
+
+// RevokeInvitation отзывает приглашение команды
+func RevokeInvitation(actor *models.User, teamID, invitationID uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	result := database.DB.Where("id = ? AND team_id = ?", invitationID, teamID).First(&invitation)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if invitation.RevokedAt == nil {
+		now := time.Now()
+		invitation.RevokedAt = &now
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&invitation).Update("revoked_at", now).Error; err != nil {
+				return err
+			}
+			return recordAudit(tx, actor, teamID, models.AuditInvitationRevoked, models.AuditTargetInvitation, invitation.ID, nil, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &invitation, nil
+}
+
+// RedeemInvitation добавляет пользователя в команду по коду приглашения.
+// Приглашение блокируется на время транзакции, чтобы не превысить лимит использований.
+func RedeemInvitation(user *models.User, token string) (*models.Team, error) {
+	var team models.Team
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var invitation models.Invitation
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token = ?", token).First(&invitation)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := checkInvitation(&invitation, user, time.Now()); err != nil {
+			return err
+		}
+
+		if err := tx.First(&team, invitation.TeamID).Error; err != nil {
+			return ErrTeamNotFound
+		}
+
+		if err := addTeamMember(tx, user, &team); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&invitation).Update("uses", gorm.Expr("uses + 1")).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.InvitationRedemption{InvitationID: invitation.ID, UserID: user.ID}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, user, team.ID, models.AuditMemberJoined, models.AuditTargetUser, user.ID, nil,
+			map[string]uint{"invitation_id": invitation.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// checkInvitation проверяет, может ли пользователь воспользоваться приглашением
+func checkInvitation(invitation *models.Invitation, user *models.User, now time.Time) error {
+	switch {
+	case invitation.RevokedAt != nil:
+		return ErrInvitationRevoked
+	case !now.Before(invitation.ExpiresAt):
+		return ErrInvitationExpired
+	case invitation.MaxUses > 0 && invitation.Uses >= invitation.MaxUses:
+		return ErrInvitationUsedUp
+	case invitation.TargetUserID != nil && *invitation.TargetUserID != user.ID:
+		return ErrInvitationNotForUser
+	case utils.IsTeamMember(user.ID, invitation.TeamID):
+		return ErrAlreadyInTeam
+	}
+	return nil
+}
+
+// InvitationLink строит deep link приглашения
+func InvitationLink(token string) string {
+	if BotUsername == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://t.me/%s?start=%s", BotUsername, token)
+}
+
+// newInvitationToken генерирует случайный код, допустимый в параметре start
+func newInvitationToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
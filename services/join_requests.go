@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateJoinRequest создает заявку на вступление и уведомляет участников с правом приглашать
+func CreateJoinRequest(user *models.User, teamID uint, message string) (*models.JoinRequest, error) {
+	if utils.IsTeamMember(user.ID, teamID) {
+		return nil, ErrAlreadyInTeam
+	}
+
+	var team models.Team
+	result := database.DB.First(&team, teamID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrTeamNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var pending int64
+	database.DB.Model(&models.JoinRequest{}).
+		Where("team_id = ? AND user_id = ? AND status = ?", team.ID, user.ID, models.JoinRequestPending).
+		Count(&pending)
+	if pending > 0 {
+		return nil, ErrJoinRequestExists
+	}
+
+	request := models.JoinRequest{
+		TeamID:  team.ID,
+		UserID:  user.ID,
+		Status:  models.JoinRequestPending,
+		Message: message,
+	}
+	if err := database.DB.Create(&request).Error; err != nil {
+		return nil, err
+	}
+	request.Team = &team
+	request.User = user
+
+	reviewers, err := TeamMembersWithPermission(team.ID, models.PermissionInviteMembers)
+	if err != nil {
+		return nil, err
+	}
+	notify(func(n Notifier) { n.NotifyJoinRequest(&request, reviewers) })
+
+	return &request, nil
+}
+
+// ListJoinRequests возвращает заявки команды; пустой status — все заявки
+func ListJoinRequests(teamID uint, status string) ([]models.JoinRequest, error) {
+	query := database.DB.Preload("User").Where("team_id = ?", teamID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var requests []models.JoinRequest
+	if err := query.Order("created_at DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// ListUserJoinRequests возвращает заявки пользователя
+func ListUserJoinRequests(userID uint) ([]models.JoinRequest, error) {
+	var requests []models.JoinRequest
+	result := database.DB.Preload("Team").Where("user_id = ?", userID).Order("created_at DESC").Find(&requests)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return requests, nil
+}
+
+// ApproveJoinRequest одобряет заявку и добавляет кандидата в команду
+func ApproveJoinRequest(reviewer *models.User, requestID uint) (*models.JoinRequest, error) {
+	return reviewJoinRequest(reviewer, requestID, models.JoinRequestApproved)
+}
+
+// RejectJoinRequest отклоняет заявку
+func RejectJoinRequest(reviewer *models.User, requestID uint) (*models.JoinRequest, error) {
+	return reviewJoinRequest(reviewer, requestID, models.JoinRequestRejected)
+}
+
+// WithdrawJoinRequest отзывает собственную заявку пользователя
+func WithdrawJoinRequest(user *models.User, requestID uint) (*models.JoinRequest, error) {
+	var request models.JoinRequest
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockJoinRequest(tx, requestID, &request); err != nil {
+			return err
+		}
+		if request.UserID != user.ID {
+			return ErrJoinRequestNotFound
+		}
+		if request.Status != models.JoinRequestPending {
+			return ErrJoinRequestNotPending
+		}
+
+		request.Status = models.JoinRequestWithdrawn
+		if err := tx.Model(&request).Update("status", request.Status).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, user, request.TeamID, models.AuditJoinRequestWithdrawn, models.AuditTargetJoinRequest, request.ID, nil,
+			map[string]uint{"user_id": request.UserID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// reviewJoinRequest переводит ожидающую заявку в статус status.
+// При одобрении выполняется та же логика вступления, что и по приглашению.
+func reviewJoinRequest(reviewer *models.User, requestID uint, status string) (*models.JoinRequest, error) {
+	var request models.JoinRequest
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockJoinRequest(tx, requestID, &request); err != nil {
+			return err
+		}
+		if !utils.HasTeamPermission(reviewer.ID, request.TeamID, models.PermissionInviteMembers) {
+			return &PermissionError{Permission: models.PermissionInviteMembers}
+		}
+		if request.Status != models.JoinRequestPending {
+			return ErrJoinRequestNotPending
+		}
+
+		var team models.Team
+		if err := tx.First(&team, request.TeamID).Error; err != nil {
+			return ErrTeamNotFound
+		}
+		var applicant models.User
+		if err := tx.First(&applicant, request.UserID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		request.Team = &team
+		request.User = &applicant
+
+		if status == models.JoinRequestApproved {
+			if utils.IsTeamMember(applicant.ID, team.ID) {
+				return ErrAlreadyInTeam
+			}
+			if err := addTeamMember(tx, &applicant, &team); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		request.Status = status
+		request.ReviewerID = &reviewer.ID
+		request.ReviewedAt = &now
+		if err := tx.Model(&request).Select("status", "reviewer_id", "reviewed_at").Updates(&request).Error; err != nil {
+			return err
+		}
+
+		action := models.AuditJoinRequestRejected
+		if status == models.JoinRequestApproved {
+			action = models.AuditJoinRequestApproved
+		}
+		return recordAudit(tx, reviewer, request.TeamID, action, models.AuditTargetJoinRequest, request.ID, nil,
+			map[string]uint{"user_id": request.UserID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notify(func(n Notifier) { n.NotifyJoinRequestResolved(&request) })
+	return &request, nil
+}
+
+// FindJoinRequest находит заявку по ID
+func FindJoinRequest(requestID uint) (*models.JoinRequest, error) {
+	var request models.JoinRequest
+	result := database.DB.First(&request, requestID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrJoinRequestNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &request, nil
+}
+
+func lockJoinRequest(tx *gorm.DB, requestID uint, request *models.JoinRequest) error {
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(request, requestID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return ErrJoinRequestNotFound
+	}
+	return result.Error
+}
+
+// TeamMembersWithPermission возвращает участников команды, у которых есть право
+func TeamMembersWithPermission(teamID uint, permission string) ([]models.User, error) {
+	var members []models.User
+	if err := database.DB.Where("id IN (?)", teamMemberIDs(teamID)).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	var result []models.User
+	for _, member := range members {
+		if utils.HasTeamPermission(member.ID, teamID, permission) {
+			result = append(result, member)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KickMember исключает участника из команды.
+// Исключить можно только участника с более низкой ролью; владельца исключить нельзя.
+func KickMember(actor *models.User, teamID, memberID uint) (*models.User, error) {
+	team, err := GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := FindTeamMember(teamID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.ID == actor.ID || team.CreatedBy == member.ID ||
+		utils.TeamRoleLevel(actor.ID, teamID) <= utils.TeamRoleLevel(member.ID, teamID) {
+		return nil, ErrRoleHierarchy
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := removeTeamMember(tx, member, teamID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditMemberKicked, models.AuditTargetUser, member.ID, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notify(func(n Notifier) { n.NotifyMemberKicked(team, member) })
+	return member, nil
+}
+
+// TransferOwnership передает команду другому участнику.
+// Роль владельца и Team.CreatedBy меняются в одной транзакции; прежний владелец остается участником.
+func TransferOwnership(owner *models.User, teamID, newOwnerID uint) (*models.Team, *models.User, error) {
+	var team models.Team
+	var newOwner *models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, teamID)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if team.CreatedBy != owner.ID {
+			return ErrNotTeamOwner
+		}
+		if newOwnerID == owner.ID {
+			return ErrAlreadyOwner
+		}
+
+		var err error
+		newOwner, err = FindTeamMember(teamID, newOwnerID)
+		if err != nil {
+			return err
+		}
+
+		var ownerRole models.Role
+		if err := tx.Where("name = ? AND team_id = ?", models.RoleOwner, teamID).First(&ownerRole).Error; err != nil {
+			return ErrRoleNotFound
+		}
+		memberRole, err := teamMemberRole(tx, teamID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(owner).Association("Roles").Delete(&ownerRole); err != nil {
+			return err
+		}
+		if err := tx.Model(owner).Association("Roles").Append(memberRole); err != nil {
+			return err
+		}
+		if err := tx.Model(newOwner).Association("Roles").Append(&ownerRole); err != nil {
+			return err
+		}
+
+		team.CreatedBy = newOwner.ID
+		if err := tx.Model(&team).Update("created_by", newOwner.ID).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, owner, teamID, models.AuditOwnershipTransferred, models.AuditTargetUser, newOwner.ID,
+			map[string]uint{"owner_id": owner.ID}, map[string]uint{"owner_id": newOwner.ID})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notify(func(n Notifier) { n.NotifyOwnershipTransferred(&team, owner, newOwner) })
+	return &team, newOwner, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+)
+
+// ListUserMemberships возвращает членства пользователя вместе с командами в порядке вступления
+func ListUserMemberships(userID uint) ([]models.TeamMembership, error) {
+	var memberships []models.TeamMembership
+	result := database.DB.Preload("Team").Where("user_id = ?", userID).Order("joined_at, team_id").Find(&memberships)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return memberships, nil
+}
+
+// ListTeamMemberships возвращает членства участников команды вместе с пользователями
+func ListTeamMemberships(teamID uint) ([]models.TeamMembership, error) {
+	var memberships []models.TeamMembership
+	result := database.DB.Preload("User").Where("team_id = ?", teamID).Order("joined_at, user_id").Find(&memberships)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return memberships, nil
+}
+
+// CurrentTeamID возвращает команду, с которой пользователь работает в боте:
+// выбранную, если он в ней состоит, иначе первую по времени вступления.
+func CurrentTeamID(user *models.User) (uint, error) {
+	if user.CurrentTeamID != nil && utils.IsTeamMember(user.ID, *user.CurrentTeamID) {
+		return *user.CurrentTeamID, nil
+	}
+
+	var membership models.TeamMembership
+	result := database.DB.Where("user_id = ?", user.ID).Order("joined_at, team_id").First(&membership)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return 0, ErrNotInTeam
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return membership.TeamID, nil
+}
+
+// SetCurrentTeam выбирает команду, с которой пользователь работает в боте
+func SetCurrentTeam(user *models.User, teamID uint) (*models.Team, error) {
+	if !utils.IsTeamMember(user.ID, teamID) {
+		return nil, ErrNotInTeam
+	}
+
+	team, err := GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Model(user).Update("current_team_id", teamID).Error; err != nil {
+		return nil, err
+	}
+	user.CurrentTeamID = &teamID
+	return team, nil
+}
+
+// teamMemberIDs подзапрос ID участников команды
+func teamMemberIDs(teamID uint) *gorm.DB {
+	return database.DB.Model(&models.TeamMembership{}).Select("user_id").Where("team_id = ?", teamID)
+}
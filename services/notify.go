@@ -0,0 +1,33 @@
+package services
+
+import "valorant-app/models"
+
+// Notifier доставляет пользователям уведомления о событиях в командах.
+// Реализуется ботом и подключается при запуске через SetNotifier.
+type Notifier interface {
+	// NotifyJoinRequest отправляет рецензентам заявку с кнопками одобрения и отклонения
+	NotifyJoinRequest(request *models.JoinRequest, reviewers []models.User)
+	// NotifyJoinRequestResolved сообщает кандидату о решении по заявке
+	NotifyJoinRequestResolved(request *models.JoinRequest)
+	// NotifyMemberKicked сообщает участнику об исключении из команды
+	NotifyMemberKicked(team *models.Team, member *models.User)
+	// NotifyOwnershipTransferred сообщает прежнему и новому владельцу о передаче команды
+	NotifyOwnershipTransferred(team *models.Team, previous, next *models.User)
+	// NotifyTeamDisbanded сообщает бывшим участникам о роспуске команды
+	NotifyTeamDisbanded(team *models.Team, members []models.User)
+}
+
+var notifier Notifier
+
+// SetNotifier подключает доставку уведомлений
+func SetNotifier(n Notifier) {
+	notifier = n
+}
+
+// notify вызывает fn в отдельной горутине, если уведомления подключены
+func notify(fn func(n Notifier)) {
+	if notifier == nil {
+		return
+	}
+	go fn(notifier)
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"errors"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateOrganization создает организацию; создатель становится ее владельцем
+func CreateOrganization(owner *models.User, name, description string) (*models.Organization, error) {
+	organization := models.Organization{
+		Name:        name,
+		Description: description,
+		CreatedBy:   owner.ID,
+	}
+	if err := database.DB.Create(&organization).Error; err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// GetOrganization возвращает организацию вместе с командами и ролями участников
+func GetOrganization(organizationID uint) (*models.Organization, error) {
+	var organization models.Organization
+	result := database.DB.Preload("Teams").Preload("Members.User").First(&organization, organizationID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &organization, nil
+}
+
+// ListUserOrganizations возвращает организации, которыми пользователь владеет или в которых имеет роль
+func ListUserOrganizations(userID uint) ([]models.Organization, error) {
+	var organizations []models.Organization
+	result := database.DB.Preload("Teams").
+		Where("created_by = ? OR id IN (?)", userID,
+			database.DB.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ?", userID)).
+		Order("id").Find(&organizations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return organizations, nil
+}
+
+// AddOrganizationTeam добавляет команду в организацию.
+// Нужны роль владельца или менеджера организации и владение командой.
+func AddOrganizationTeam(actor *models.User, organizationID, teamID uint) (*models.Team, error) {
+	if _, err := findOrganization(organizationID); err != nil {
+		return nil, err
+	}
+	if !canManageOrganization(actor.ID, organizationID) {
+		return nil, ErrOrganizationAccess
+	}
+
+	var team models.Team
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, teamID)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if team.CreatedBy != actor.ID {
+			return ErrNotTeamOwner
+		}
+		if team.OrganizationID != nil {
+			return ErrTeamInOrganization
+		}
+
+		team.OrganizationID = &organizationID
+		if err := tx.Model(&team).Update("organization_id", organizationID).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, team.ID, models.AuditTeamOrganization, models.AuditTargetTeam, team.ID,
+			nil, map[string]uint{"organization_id": organizationID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// RemoveOrganizationTeam выводит команду из организации.
+// Доступно владельцу или менеджеру организации и владельцу команды.
+func RemoveOrganizationTeam(actor *models.User, organizationID, teamID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var team models.Team
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, teamID)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if team.OrganizationID == nil || *team.OrganizationID != organizationID {
+			return ErrTeamNotInOrganization
+		}
+		if team.CreatedBy != actor.ID && !canManageOrganization(actor.ID, organizationID) {
+			return ErrOrganizationAccess
+		}
+
+		team.OrganizationID = nil
+		if err := tx.Model(&team).Update("organization_id", nil).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, team.ID, models.AuditTeamOrganization, models.AuditTargetTeam, team.ID,
+			map[string]uint{"organization_id": organizationID}, nil)
+	})
+}
+
+// SetOrganizationMember назначает пользователю роль организации (manager, head_coach).
+// Доступно только владельцу организации.
+func SetOrganizationMember(actor *models.User, organizationID, userID uint, role string) (*models.OrganizationMember, error) {
+	organization, err := findOrganization(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if organization.CreatedBy != actor.ID {
+		return nil, ErrOrganizationAccess
+	}
+	if !models.IsAssignableOrgRole(role) {
+		return nil, ErrUnknownOrgRole
+	}
+	if userID == organization.CreatedBy {
+		return nil, ErrProtectedRole
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	member := models.OrganizationMember{OrganizationID: organizationID, UserID: userID, Role: role}
+	err = database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(&member).Error
+	if err != nil {
+		return nil, err
+	}
+
+	member.User = &user
+	return &member, nil
+}
+
+// RemoveOrganizationMember снимает с пользователя роль организации. Доступно только владельцу организации.
+func RemoveOrganizationMember(actor *models.User, organizationID, userID uint) error {
+	organization, err := findOrganization(organizationID)
+	if err != nil {
+		return err
+	}
+	if organization.CreatedBy != actor.ID {
+		return ErrOrganizationAccess
+	}
+
+	result := database.DB.Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&models.OrganizationMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOrganizationMemberNotFound
+	}
+	return nil
+}
+
+// RequireOrganizationViewer проверяет, что пользователь может смотреть организацию и ее статистику:
+// владелец, пользователь с ролью в организации или участник одной из ее команд
+func RequireOrganizationViewer(user *models.User, organizationID uint) error {
+	if _, err := findOrganization(organizationID); err != nil {
+		return err
+	}
+	if utils.OrganizationMemberRole(user.ID, organizationID) != "" {
+		return nil
+	}
+
+	var count int64
+	err := database.DB.Model(&models.TeamMembership{}).
+		Joins("JOIN teams ON teams.id = team_memberships.team_id AND teams.deleted_at IS NULL").
+		Where("team_memberships.user_id = ? AND teams.organization_id = ?", user.ID, organizationID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrOrganizationViewAccess
+	}
+	return nil
+}
+
+// findOrganization находит организацию без связанных данных
+func findOrganization(organizationID uint) (*models.Organization, error) {
+	var organization models.Organization
+	result := database.DB.First(&organization, organizationID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &organization, nil
+}
+
+// canManageOrganization проверяет, что пользователь владелец или менеджер организации
+func canManageOrganization(userID, organizationID uint) bool {
+	switch utils.OrganizationMemberRole(userID, organizationID) {
+	case models.OrgRoleOwner, models.OrgRoleManager:
+		return true
+	}
+	return false
+}
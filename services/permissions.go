@@ -0,0 +1,66 @@
+package services
+
+import (
+	"valorant-app/models"
+	"valorant-app/utils"
+)
+
+// RequireTeamPermission проверяет, что у пользователя есть право в текущей команде (см. CurrentTeamID).
+// Возвращает ID этой команды.
+func RequireTeamPermission(user *models.User, permission string) (uint, error) {
+	teamID, err := CurrentTeamID(user)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := CheckTeamPermission(user, teamID, permission); err != nil {
+		return 0, err
+	}
+	return teamID, nil
+}
+
+// CheckTeamPermission проверяет, что у пользователя есть право в команде teamID
+func CheckTeamPermission(user *models.User, teamID uint, permission string) error {
+	if !utils.HasTeamPermission(user.ID, teamID, permission) {
+		return &PermissionError{Permission: permission}
+	}
+	return nil
+}
+
+// PermissionInfo право каталога с описанием на языке запроса
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PermissionGroupInfo группа прав каталога с названием на языке запроса
+type PermissionGroupInfo struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title"`
+	Permissions []PermissionInfo `json:"permissions"`
+}
+
+// PermissionCatalog возвращает каталог прав, сгруппированный и локализованный для языка lang.
+// Пустые группы не возвращаются.
+func PermissionCatalog(lang string) []PermissionGroupInfo {
+	byGroup := make(map[string][]PermissionInfo)
+	for _, definition := range models.RegisteredPermissions() {
+		byGroup[definition.Group] = append(byGroup[definition.Group], PermissionInfo{
+			Name:        definition.Name,
+			Description: models.Localized(definition.Descriptions, lang),
+		})
+	}
+
+	groups := make([]PermissionGroupInfo, 0, len(byGroup))
+	for _, group := range models.RegisteredPermissionGroups() {
+		if len(byGroup[group.Name]) == 0 {
+			continue
+		}
+		groups = append(groups, PermissionGroupInfo{
+			Name:        group.Name,
+			Title:       models.Localized(group.Titles, lang),
+			Permissions: byGroup[group.Name],
+		})
+	}
+	return groups
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"valorant-app/database"
+	"valorant-app/models"
+)
+
+// UserSyncResult итог синхронизации всех аккаунтов пользователя
+type UserSyncResult struct {
+	NewMatches     int           `json:"new_matches"`
+	SkippedMatches int           `json:"skipped_matches"`
+	FailedMatches  int           `json:"failed_matches"`
+	FailedAccounts int           `json:"failed_accounts"`
+	Accounts       []*SyncResult `json:"accounts"`
+}
+
+// LinkValorantPlayer привязывает аккаунт Valorant к пользователю
+func LinkValorantPlayer(user *models.User, gameName, tag, region string) (*models.ValorantPlayer, error) {
+	if !models.IsValorantRegion(region) {
+		return nil, ErrInvalidRegion
+	}
+
+	player := models.ValorantPlayer{
+		UserID:   user.ID,
+		GameName: gameName,
+		Tag:      tag,
+		Region:   region,
+	}
+	if err := database.DB.Create(&player).Error; err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+// GetUserPlayers возвращает аккаунты Valorant пользователя вместе со статистикой
+func GetUserPlayers(userID uint) ([]models.ValorantPlayer, error) {
+	var players []models.ValorantPlayer
+	result := database.DB.Preload("Stats").Where("user_id = ?", userID).Find(&players)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return players, nil
+}
+
+// GetTeamPlayers возвращает аккаунты Valorant участников команды
+func GetTeamPlayers(teamID uint) ([]models.ValorantPlayer, error) {
+	var players []models.ValorantPlayer
+	result := database.DB.Preload("User").Where("user_id IN (?)", teamMemberIDs(teamID)).Find(&players)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return players, nil
+}
+
+// SyncUserPlayers синхронизирует все аккаунты Valorant пользователя.
+// Ошибка аккаунта записывается в его SyncResult, и синхронизация продолжается со следующего;
+// ошибка возвращается, только если не удалось синхронизировать ни один аккаунт.
+func SyncUserPlayers(ctx context.Context, provider ValorantProvider, user *models.User) (*UserSyncResult, error) {
+	players, err := GetUserPlayers(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(players) == 0 {
+		return nil, ErrPlayerNotLinked
+	}
+
+	result := &UserSyncResult{Accounts: make([]*SyncResult, 0, len(players))}
+	var lastErr error
+	for i := range players {
+		// После отмены или таймаута остальные аккаунты не трогаем, чтобы не записать им неудачу
+		if ctx.Err() != nil {
+			break
+		}
+
+		syncResult, err := SyncPlayer(ctx, provider, &players[i])
+		if err != nil {
+			lastErr = fmt.Errorf("sync player %d: %w", players[i].ID, err)
+			result.FailedAccounts++
+			result.Accounts = append(result.Accounts, &SyncResult{PlayerID: players[i].ID, Player: &players[i], Error: err.Error()})
+			continue
+		}
+
+		result.NewMatches += syncResult.NewMatches
+		result.SkippedMatches += syncResult.SkippedMatches
+		result.FailedMatches += syncResult.FailedMatches
+		result.Accounts = append(result.Accounts, syncResult)
+	}
+
+	if lastErr != nil && result.FailedAccounts == len(result.Accounts) {
+		return nil, lastErr
+	}
+	return result, nil
+}
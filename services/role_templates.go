@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+)
+
+// RoleTemplateUpdate изменяемые поля шаблона роли; nil — оставить без изменений
+type RoleTemplateUpdate struct {
+	Title       *string
+	Description *string
+	Permissions []string
+}
+
+// ListRoleTemplates возвращает шаблоны ролей команды.
+// Шаблоны создаются вместе с командой (для старых команд — при запуске), чтение их не создает.
+func ListRoleTemplates(teamID uint) ([]models.RoleTemplate, error) {
+	var templates []models.RoleTemplate
+	if err := database.DB.Preload("Permissions").Where("team_id = ?", teamID).Order("id").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// UpdateRoleTemplate изменяет шаблон роли команды и одноименную роль команды.
+// Доступно только владельцу; шаблон владельца изменить нельзя.
+func UpdateRoleTemplate(user *models.User, teamID, templateID uint, update RoleTemplateUpdate) (*models.RoleTemplate, error) {
+	if !utils.IsTeamOwner(user.ID, teamID) {
+		return nil, ErrNotTeamOwner
+	}
+
+	var template models.RoleTemplate
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND team_id = ?", templateID, teamID).First(&template)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrRoleTemplateNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if template.Name == models.RoleOwner {
+			return ErrProtectedRole
+		}
+		if err := tx.Model(&template).Association("Permissions").Find(&template.Permissions); err != nil {
+			return err
+		}
+		before := snapshotRoleTemplate(&template)
+
+		if update.Title != nil {
+			template.Title = *update.Title
+		}
+		if update.Description != nil {
+			template.Description = *update.Description
+		}
+		if err := tx.Model(&template).Select("title", "description").Updates(&template).Error; err != nil {
+			return err
+		}
+
+		// Роль команды, созданная из шаблона, получает те же изменения
+		var role models.Role
+		hasRole := tx.Where("team_id = ? AND name = ?", teamID, template.Name).First(&role).Error == nil
+		if hasRole && update.Title != nil {
+			if err := tx.Model(&role).Update("description", template.Title).Error; err != nil {
+				return err
+			}
+		}
+
+		if update.Permissions != nil {
+			permissions, err := findPermissions(tx, update.Permissions)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&template).Association("Permissions").Replace(permissions); err != nil {
+				return err
+			}
+			if hasRole {
+				if err := tx.Model(&role).Association("Permissions").Replace(permissions); err != nil {
+					return err
+				}
+			}
+			template.Permissions = permissions
+		}
+
+		return recordAudit(tx, user, teamID, models.AuditRoleTemplateUpdated, models.AuditTargetRoleTemplate, template.ID,
+			before, snapshotRoleTemplate(&template))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Preload("Permissions").First(&template, template.ID).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// findPermissions находит права по названиям; неизвестное название — ошибка
+func findPermissions(tx *gorm.DB, names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	if err := tx.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, &UnknownPermissionError{Name: name}
+		}
+	}
+	return permissions, nil
+}
@@ -0,0 +1,469 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+)
+
+// GetTeamRoles возвращает роли команды с правами, от старшей к младшей
+func GetTeamRoles(teamID uint) ([]models.Role, error) {
+	var roles []models.Role
+	result := database.DB.Preload("Permissions").Where("team_id = ?", teamID).Order("position DESC, id").Find(&roles)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return roles, nil
+}
+
+// CreateRole создает роль в команде.
+// Позиция роли должна быть ниже старшей роли создателя, а права — входить в его права.
+func CreateRole(actor *models.User, teamID uint, name, description string, position int, permissionNames []string) (*models.Role, error) {
+	if position >= utils.TeamRoleLevel(actor.ID, teamID) {
+		return nil, ErrRoleHierarchy
+	}
+
+	var count int64
+	database.DB.Model(&models.Role{}).Where("team_id = ? AND name = ?", teamID, name).Count(&count)
+	if count > 0 {
+		return nil, ErrRoleNameTaken
+	}
+
+	permissions, err := findPermissions(database.DB, permissionNames)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGrantable(actor, teamID, permissions); err != nil {
+		return nil, err
+	}
+
+	role := models.Role{
+		Name:        name,
+		Description: description,
+		TeamID:      teamID,
+		Position:    position,
+		Permissions: permissions,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditRoleCreated, models.AuditTargetRole, role.ID, nil, snapshotRole(&role))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// RoleUpdate изменяемые поля роли; nil — оставить без изменений
+type RoleUpdate struct {
+	Name        *string
+	Description *string
+	Position    *int
+	Permissions []string // nil — не менять, пустой список — снять все права
+}
+
+// UpdateRole изменяет роль команды: название, описание, позицию и набор прав
+func UpdateRole(actor *models.User, teamID, roleID uint, update RoleUpdate) (*models.Role, error) {
+	role, err := manageableRole(actor, teamID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshotRole(role)
+
+	if update.Name != nil && *update.Name != role.Name {
+		var count int64
+		database.DB.Model(&models.Role{}).Where("team_id = ? AND name = ? AND id <> ?", teamID, *update.Name, role.ID).Count(&count)
+		if count > 0 {
+			return nil, ErrRoleNameTaken
+		}
+		role.Name = *update.Name
+	}
+	if update.Description != nil {
+		role.Description = *update.Description
+	}
+	if update.Position != nil {
+		if *update.Position >= utils.TeamRoleLevel(actor.ID, teamID) {
+			return nil, ErrRoleHierarchy
+		}
+		role.Position = *update.Position
+	}
+
+	var permissions []models.Permission
+	if update.Permissions != nil {
+		permissions, err = findPermissions(database.DB, update.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkGrantable(actor, teamID, addedPermissions(role.Permissions, permissions)); err != nil {
+			return nil, err
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Шаблон связан с ролью по названию: переименовываем его вместе с ролью, иначе при следующем
+		// заполнении ролей по шаблонам появится дубль под старым названием
+		if role.Name != before.Name {
+			err := tx.Model(&models.RoleTemplate{}).Where("team_id = ? AND name = ?", teamID, before.Name).
+				Update("name", role.Name).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(role).Select("name", "description", "position").Updates(role).Error; err != nil {
+			return err
+		}
+		if update.Permissions != nil {
+			if err := tx.Model(role).Association("Permissions").Replace(permissions); err != nil {
+				return err
+			}
+			role.Permissions = permissions
+		}
+		return recordAudit(tx, actor, teamID, models.AuditRoleUpdated, models.AuditTargetRole, role.ID, before, snapshotRole(role))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// DeleteRole удаляет роль команды и снимает ее со всех участников
+func DeleteRole(actor *models.User, teamID, roleID uint) error {
+	role, err := manageableRole(actor, teamID, roleID)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Delete(role).Error; err != nil {
+			return err
+		}
+		// Удаляем и шаблон роли, иначе роль будет снова создана по нему
+		if err := tx.Where("team_id = ? AND name = ?", teamID, role.Name).Delete(&models.RoleTemplate{}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditRoleDeleted, models.AuditTargetRole, role.ID, snapshotRole(role), nil)
+	})
+}
+
+// AddRolePermissions добавляет роли права по названиям
+func AddRolePermissions(actor *models.User, teamID, roleID uint, names []string) (*models.Role, error) {
+	role, err := manageableRole(actor, teamID, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := findPermissions(database.DB, names)
+	if err != nil {
+		return nil, err
+	}
+	added := addedPermissions(role.Permissions, permissions)
+	if err := checkGrantable(actor, teamID, added); err != nil {
+		return nil, err
+	}
+	if len(added) == 0 {
+		return role, nil
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Association("Permissions").Append(added); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditRolePermissionAdded, models.AuditTargetRole, role.ID,
+			nil, permissionNames(added))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return FindTeamRoleWithPermissions(teamID, role.ID)
+}
+
+// RemoveRolePermission снимает с роли право по названию
+func RemoveRolePermission(actor *models.User, teamID, roleID uint, name string) (*models.Role, error) {
+	role, err := manageableRole(actor, teamID, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := findPermissions(database.DB, []string{name})
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Association("Permissions").Delete(permissions); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditRolePermissionRemoved, models.AuditTargetRole, role.ID,
+			permissionNames(permissions), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return FindTeamRoleWithPermissions(teamID, role.ID)
+}
+
+// FindTeamRoleWithPermissions находит роль команды по ID вместе с правами
+func FindTeamRoleWithPermissions(teamID, roleID uint) (*models.Role, error) {
+	var role models.Role
+	result := database.DB.Preload("Permissions").Where("id = ? AND team_id = ?", roleID, teamID).First(&role)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	return &role, result.Error
+}
+
+// FindTeamRole находит роль команды по ID
+func FindTeamRole(teamID, roleID uint) (*models.Role, error) {
+	var role models.Role
+	result := database.DB.Where("id = ? AND team_id = ?", roleID, teamID).First(&role)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	return &role, result.Error
+}
+
+// FindTeamRoleByName находит роль команды по названию
+func FindTeamRoleByName(teamID uint, name string) (*models.Role, error) {
+	var role models.Role
+	result := database.DB.Where("name = ? AND team_id = ?", name, teamID).First(&role)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	return &role, result.Error
+}
+
+// FindTeamMember находит участника команды по ID пользователя
+func FindTeamMember(teamID, userID uint) (*models.User, error) {
+	var user models.User
+	result := database.DB.Where("id = ? AND id IN (?)", userID, teamMemberIDs(teamID)).First(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotInTeam
+	}
+	return &user, result.Error
+}
+
+// AssignRole назначает роль участнику команды.
+// Назначать можно только роли ниже своей старшей роли и только участникам ниже себя.
+func AssignRole(actor *models.User, teamID, userID, roleID uint) error {
+	role, user, err := manageableMemberRole(actor, teamID, userID, roleID)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Association("Roles").Append(role); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditRoleAssigned, models.AuditTargetUser, user.ID, nil, role.Name)
+	})
+}
+
+// RemoveRole снимает роль с участника команды по тем же правилам, что и AssignRole
+func RemoveRole(actor *models.User, teamID, userID, roleID uint) error {
+	role, user, err := manageableMemberRole(actor, teamID, userID, roleID)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Association("Roles").Delete(role); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditRoleUnassigned, models.AuditTargetUser, user.ID, role.Name, nil)
+	})
+}
+
+// SetRolePosition перемещает роль на позицию position в иерархии
+func SetRolePosition(actor *models.User, teamID, roleID uint, position int) (*models.Role, error) {
+	role, err := FindTeamRole(teamID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoleManageable(actor, teamID, role); err != nil {
+		return nil, err
+	}
+	if position >= utils.TeamRoleLevel(actor.ID, teamID) {
+		return nil, ErrRoleHierarchy
+	}
+
+	before := role.Position
+	role.Position = position
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Update("position", position).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditRoleMoved, models.AuditTargetRole, role.ID, before, position)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// ReorderRoles переставляет роли в порядке roleIDs (от старшей к младшей).
+// Роли обмениваются своими текущими позициями, поэтому ни одна не поднимается выше самой старшей из них.
+func ReorderRoles(actor *models.User, teamID uint, roleIDs []uint) ([]models.Role, error) {
+	roles := make([]models.Role, 0, len(roleIDs))
+	positions := make([]int, 0, len(roleIDs))
+	seen := make(map[uint]bool, len(roleIDs))
+	for _, roleID := range roleIDs {
+		if seen[roleID] {
+			continue
+		}
+		seen[roleID] = true
+
+		role, err := FindTeamRole(teamID, roleID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRoleManageable(actor, teamID, role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+		positions = append(positions, role.Position)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(positions)))
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range roles {
+			if roles[i].Position == positions[i] {
+				continue
+			}
+			before := roles[i].Position
+			roles[i].Position = positions[i]
+			if err := tx.Model(&roles[i]).Update("position", positions[i]).Error; err != nil {
+				return err
+			}
+			err := recordAudit(tx, actor, teamID, models.AuditRoleMoved, models.AuditTargetRole, roles[i].ID, before, positions[i])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// manageableMemberRole находит роль и участника команды и проверяет, что actor может
+// назначать и снимать эту роль у этого участника
+func manageableMemberRole(actor *models.User, teamID, userID, roleID uint) (*models.Role, *models.User, error) {
+	role, err := FindTeamRole(teamID, roleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := FindTeamMember(teamID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkRoleManageable(actor, teamID, role); err != nil {
+		return nil, nil, err
+	}
+	// Участником с такой же или более высокой ролью управлять нельзя; свои младшие роли — можно
+	if user.ID != actor.ID && utils.TeamRoleLevel(user.ID, teamID) >= utils.TeamRoleLevel(actor.ID, teamID) {
+		return nil, nil, ErrRoleHierarchy
+	}
+
+	if err := database.DB.Model(role).Association("Permissions").Find(&role.Permissions); err != nil {
+		return nil, nil, err
+	}
+	if err := checkGrantable(actor, teamID, role.Permissions); err != nil {
+		return nil, nil, err
+	}
+	return role, user, nil
+}
+
+// manageableRole находит роль команды с правами и проверяет, что actor может ее изменять
+func manageableRole(actor *models.User, teamID, roleID uint) (*models.Role, error) {
+	role, err := FindTeamRoleWithPermissions(teamID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoleManageable(actor, teamID, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// addedPermissions возвращает права из next, которых нет в current
+func addedPermissions(current, next []models.Permission) []models.Permission {
+	existing := make(map[uint]bool, len(current))
+	for _, permission := range current {
+		existing[permission.ID] = true
+	}
+
+	var added []models.Permission
+	for _, permission := range next {
+		if !existing[permission.ID] {
+			added = append(added, permission)
+		}
+	}
+	return added
+}
+
+func permissionNames(permissions []models.Permission) []string {
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
+
+// checkRoleManageable проверяет, что роль ниже старшей роли actor.
+// Роль владельца меняется только передачей команды.
+func checkRoleManageable(actor *models.User, teamID uint, role *models.Role) error {
+	if role.Name == models.RoleOwner {
+		return ErrProtectedRole
+	}
+	if role.Position >= utils.TeamRoleLevel(actor.ID, teamID) {
+		return ErrRoleHierarchy
+	}
+	return nil
+}
+
+// checkGrantable проверяет, что actor сам имеет все выдаваемые права
+func checkGrantable(actor *models.User, teamID uint, permissions []models.Permission) error {
+	if utils.IsTeamOwner(actor.ID, teamID) {
+		return nil
+	}
+
+	held := make(map[string]bool)
+	for _, name := range utils.GetUserPermissions(actor.ID, teamID) {
+		held[name] = true
+	}
+	for _, permission := range permissions {
+		if !held[permission.Name] {
+			return &EscalationError{Permission: permission.Name}
+		}
+	}
+	return nil
+}
+
+// GetUserRoles возвращает роли участника в команде
+func GetUserRoles(teamID, userID uint) ([]models.Role, error) {
+	var user models.User
+	result := database.DB.Preload("Roles", "team_id = ?", teamID).Where("id = ? AND id IN (?)", userID, teamMemberIDs(teamID)).First(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotInTeam
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return user.Roles, nil
+}
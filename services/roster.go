@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Roster состав команды, сгруппированный по позициям
+type Roster struct {
+	TeamID      uint                    `json:"team_id"`
+	RosterLimit int                     `json:"roster_limit"`
+	MaxStarters int                     `json:"max_starters"`
+	Starters    []models.TeamMembership `json:"starters"`
+	Substitutes []models.TeamMembership `json:"substitutes"`
+	Staff       []models.TeamMembership `json:"staff"` // Тренеры, аналитики, менеджеры
+}
+
+// RosterUpdate изменяемые поля участника в составе; nil — оставить без изменений
+type RosterUpdate struct {
+	RosterSlot *string
+	Status     *string
+	InGameRole *string
+	MainAgents []string
+}
+
+// managesRoster сообщает, меняет ли обновление позицию или статус — это доступно только с manage_team
+func (u RosterUpdate) managesRoster() bool {
+	return u.RosterSlot != nil || u.Status != nil
+}
+
+// GetRoster возвращает состав команды по позициям
+func GetRoster(teamID uint) (*Roster, error) {
+	team, err := findTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := ListTeamMemberships(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := &Roster{
+		TeamID:      team.ID,
+		RosterLimit: team.RosterLimit,
+		MaxStarters: models.MaxStarters,
+		Starters:    []models.TeamMembership{},
+		Substitutes: []models.TeamMembership{},
+		Staff:       []models.TeamMembership{},
+	}
+	for _, membership := range memberships {
+		switch membership.RosterSlot {
+		case models.RosterStarter:
+			roster.Starters = append(roster.Starters, membership)
+		case models.RosterSub:
+			roster.Substitutes = append(roster.Substitutes, membership)
+		default:
+			roster.Staff = append(roster.Staff, membership)
+		}
+	}
+	return roster, nil
+}
+
+// UpdateRosterEntry изменяет позицию, статус, игровую роль и основных агентов участника.
+// Участник может сам менять свою игровую роль и агентов; остальное требует права manage_team.
+func UpdateRosterEntry(actor *models.User, teamID, userID uint, update RosterUpdate) (*models.TeamMembership, error) {
+	if actor.ID != userID || update.managesRoster() {
+		if !utils.HasTeamPermission(actor.ID, teamID, models.PermissionManageTeam) {
+			return nil, &PermissionError{Permission: models.PermissionManageTeam}
+		}
+	}
+
+	if update.RosterSlot != nil && !models.IsRosterSlot(*update.RosterSlot) {
+		return nil, ErrInvalidRosterSlot
+	}
+	if update.Status != nil && !models.IsMembershipStatus(*update.Status) {
+		return nil, ErrInvalidStatus
+	}
+	if update.InGameRole != nil && *update.InGameRole != "" && !models.IsInGameRole(*update.InGameRole) {
+		return nil, ErrInvalidInGameRole
+	}
+	agents := normalizeAgents(update.MainAgents)
+	if len(agents) > models.MaxMainAgents {
+		return nil, ErrTooManyMainAgents
+	}
+
+	var membership models.TeamMembership
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокировка команды сериализует изменения состава и проверку лимита основы
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Team{}, teamID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTeamNotFound
+			}
+			return err
+		}
+
+		result := tx.Where("team_id = ? AND user_id = ?", teamID, userID).First(&membership)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrUserNotInTeam
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		before := snapshotMembership(&membership)
+
+		if update.RosterSlot != nil && *update.RosterSlot == models.RosterStarter && membership.RosterSlot != models.RosterStarter {
+			var starters int64
+			tx.Model(&models.TeamMembership{}).Where("team_id = ? AND roster_slot = ?", teamID, models.RosterStarter).Count(&starters)
+			if starters >= models.MaxStarters {
+				return ErrStartersFull
+			}
+		}
+
+		if update.RosterSlot != nil {
+			membership.RosterSlot = *update.RosterSlot
+		}
+		if update.Status != nil {
+			membership.Status = *update.Status
+		}
+		if update.InGameRole != nil {
+			membership.InGameRole = *update.InGameRole
+		}
+		if update.MainAgents != nil {
+			membership.MainAgents = agents
+		}
+
+		err := tx.Model(&membership).Select("roster_slot", "status", "in_game_role", "main_agents").Updates(&membership).Error
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditMemberRosterUpdated, models.AuditTargetUser, userID,
+			before, snapshotMembership(&membership))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// SetRosterLimit задает максимальное число участников команды; 0 — без ограничения
+func SetRosterLimit(actor *models.User, teamID uint, limit int) (*models.Team, error) {
+	var team models.Team
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, teamID)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if limit > 0 {
+			var members int64
+			tx.Model(&models.TeamMembership{}).Where("team_id = ?", teamID).Count(&members)
+			if int64(limit) < members {
+				return ErrRosterLimitTooLow
+			}
+		}
+
+		before := snapshotTeam(&team)
+		team.RosterLimit = limit
+		if err := tx.Model(&team).Update("roster_limit", limit).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditTeamRosterLimit, models.AuditTargetTeam, teamID,
+			before, snapshotTeam(&team))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// findTeam находит команду без связанных данных
+func findTeam(teamID uint) (*models.Team, error) {
+	var team models.Team
+	result := database.DB.First(&team, teamID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrTeamNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &team, nil
+}
+
+// normalizeAgents убирает пустые и повторяющиеся имена агентов
+func normalizeAgents(agents []string) []string {
+	result := make([]string, 0, len(agents))
+	seen := make(map[string]bool)
+	for _, agent := range agents {
+		agent = strings.TrimSpace(agent)
+		key := strings.ToLower(agent)
+		if agent == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, agent)
+	}
+	return result
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"sort"
+	"time"
+	"valorant-app/database"
+	"valorant-app/models"
+)
+
+// StatsFilter ограничивает выборку матчей для агрегации
+type StatsFilter struct {
+	From *time.Time `json:"from,omitempty"` // Начало периода (включительно)
+	To   *time.Time `json:"to,omitempty"`   // Конец периода (не включительно)
+	Mode string     `json:"mode,omitempty"` // Режим игры
+}
+
+// StatLine агрегированные показатели по набору матчей
+type StatLine struct {
+	Matches        int     `json:"matches"`
+	Wins           int     `json:"wins"`
+	Losses         int     `json:"losses"`
+	WinRate        float64 `json:"win_rate"`
+	AverageKills   float64 `json:"average_kills"`
+	AverageDeaths  float64 `json:"average_deaths"`
+	AverageAssists float64 `json:"average_assists"`
+	AverageScore   float64 `json:"average_score"`
+	AverageDamage  float64 `json:"average_damage"`
+	KD             float64 `json:"kd"`
+	HeadshotRate   float64 `json:"headshot_rate"`
+}
+
+// BreakdownStats показатели команды на одной карте или одном агенте
+type BreakdownStats struct {
+	Name string `json:"name"`
+	StatLine
+}
+
+// PlayerStats показатели игрока команды за выбранный период
+type PlayerStats struct {
+	PlayerID   uint                  `json:"player_id"`
+	UserID     uint                  `json:"user_id"`
+	GameName   string                `json:"game_name"`
+	Tag        string                `json:"tag"`
+	RosterSlot string                `json:"roster_slot"` // starter или sub
+	Lifetime   *models.ValorantStats `json:"lifetime,omitempty"`
+	StatLine
+}
+
+// PlayerMetric значение метрики у конкретного игрока
+type PlayerMetric struct {
+	PlayerID uint    `json:"player_id"`
+	GameName string  `json:"game_name"`
+	Value    float64 `json:"value"`
+}
+
+// MetricLeaders лучший и худший игрок по метрике
+type MetricLeaders struct {
+	Best  *PlayerMetric `json:"best"`
+	Worst *PlayerMetric `json:"worst"`
+}
+
+// TeamStats агрегированная статистика команды.
+// Командные показатели, карты, агенты и лидеры считаются по основному составу;
+// запасные есть только в Players, тренеры и другой штаб не учитываются.
+type TeamStats struct {
+	TeamID uint        `json:"team_id"`
+	Filter StatsFilter `json:"filter"`
+	// Командные матчи считаются один раз, даже если в них играли несколько участников
+	TeamMatches int                       `json:"team_matches"`
+	TeamWins    int                       `json:"team_wins"`
+	TeamWinRate float64                   `json:"team_win_rate"`
+	Totals      StatLine                  `json:"totals"`
+	Maps        []BreakdownStats          `json:"maps"`
+	Agents      []BreakdownStats          `json:"agents"`
+	Players     []PlayerStats             `json:"players"`
+	Leaders     map[string]*MetricLeaders `json:"leaders"`
+}
+
+// OrganizationTeamStats статистика команды в сводке организации
+type OrganizationTeamStats struct {
+	TeamName string `json:"team_name"`
+	*TeamStats
+}
+
+// OrganizationStats сводная статистика команд организации
+type OrganizationStats struct {
+	OrganizationID uint        `json:"organization_id"`
+	Filter         StatsFilter `json:"filter"`
+	// Сумма командных матчей всех команд организации
+	TeamMatches int     `json:"team_matches"`
+	TeamWins    int     `json:"team_wins"`
+	TeamWinRate float64 `json:"team_win_rate"`
+	// По основным составам; матч игрока, состоящего в нескольких командах организации, учитывается один раз
+	Totals StatLine                `json:"totals"`
+	Teams  []OrganizationTeamStats `json:"teams"`
+}
+
+// playerMatchRow строка матча игрока для агрегации
+type playerMatchRow struct {
+	PlayerID   uint
+	RosterSlot string
+	MatchID    uint
+	Result     string
+	Agent      string
+	Map        string
+	Kills      int
+	Deaths     int
+	Assists    int
+	Score      int
+	Damage     int
+	Headshots  int
+	Bodyshots  int
+	Legshots   int
+}
+
+// statAccumulator накапливает суммы для StatLine
+type statAccumulator struct {
+	matches, wins                                    int
+	kills, deaths, assists, score, damage, headshots int
+	shots                                            int // Все попадания в матчах, где известны попадания в тело и ноги
+}
+
+func (a *statAccumulator) add(row *playerMatchRow) {
+	a.matches++
+	if row.Result == models.MatchResultWin {
+		a.wins++
+	}
+	a.kills += row.Kills
+	a.deaths += row.Deaths
+	a.assists += row.Assists
+	a.score += row.Score
+	a.damage += row.Damage
+	// Матчи, сохраненные до учета попаданий в тело и ноги, в процент попаданий в голову не входят
+	if row.Bodyshots+row.Legshots > 0 {
+		a.headshots += row.Headshots
+		a.shots += row.Headshots + row.Bodyshots + row.Legshots
+	}
+}
+
+func (a *statAccumulator) line() StatLine {
+	line := StatLine{
+		Matches:      a.matches,
+		Wins:         a.wins,
+		Losses:       a.matches - a.wins,
+		WinRate:      percent(a.wins, a.matches),
+		HeadshotRate: percent(a.headshots, a.shots),
+	}
+	if a.matches > 0 {
+		n := float64(a.matches)
+		line.AverageKills = float64(a.kills) / n
+		line.AverageDeaths = float64(a.deaths) / n
+		line.AverageAssists = float64(a.assists) / n
+		line.AverageScore = float64(a.score) / n
+		line.AverageDamage = float64(a.damage) / n
+	}
+	if a.deaths > 0 {
+		line.KD = float64(a.kills) / float64(a.deaths)
+	} else {
+		line.KD = float64(a.kills)
+	}
+	return line
+}
+
+// leaderMetrics метрики, по которым определяются лучший и худший игроки.
+// lowerBetter означает, что лучшим считается меньшее значение.
+var leaderMetrics = map[string]struct {
+	value       func(*StatLine) float64
+	lowerBetter bool
+}{
+	"win_rate":        {func(l *StatLine) float64 { return l.WinRate }, false},
+	"kd":              {func(l *StatLine) float64 { return l.KD }, false},
+	"average_kills":   {func(l *StatLine) float64 { return l.AverageKills }, false},
+	"average_deaths":  {func(l *StatLine) float64 { return l.AverageDeaths }, true},
+	"average_assists": {func(l *StatLine) float64 { return l.AverageAssists }, false},
+	"average_score":   {func(l *StatLine) float64 { return l.AverageScore }, false},
+	"average_damage":  {func(l *StatLine) float64 { return l.AverageDamage }, false},
+	"headshot_rate":   {func(l *StatLine) float64 { return l.HeadshotRate }, false},
+}
+
+// GetTeamStats агрегирует статистику игроков команды с учетом фильтра
+func GetTeamStats(teamID uint, filter StatsFilter) (*TeamStats, error) {
+	var memberships []models.TeamMembership
+	err := database.DB.Where("team_id = ? AND roster_slot IN ?", teamID, playerSlots).Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+	slots := make(map[uint]string, len(memberships))
+	userIDs := make([]uint, 0, len(memberships))
+	for _, membership := range memberships {
+		slots[membership.UserID] = membership.RosterSlot
+		userIDs = append(userIDs, membership.UserID)
+	}
+
+	players := []models.ValorantPlayer{}
+	if len(userIDs) > 0 {
+		if err := database.DB.Preload("Stats").Where("user_id IN ?", userIDs).Find(&players).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := loadTeamMatchRows(teamID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var totals statAccumulator
+	maps := make(map[string]*statAccumulator)
+	agents := make(map[string]*statAccumulator)
+	byPlayer := make(map[uint]*statAccumulator)
+	teamResults := make(map[uint]bool)
+
+	for i := range rows {
+		row := &rows[i]
+		if byPlayer[row.PlayerID] == nil {
+			byPlayer[row.PlayerID] = &statAccumulator{}
+		}
+		byPlayer[row.PlayerID].add(row)
+
+		if row.RosterSlot != models.RosterStarter {
+			continue
+		}
+		totals.add(row)
+		accumulatorFor(maps, row.Map).add(row)
+		accumulatorFor(agents, row.Agent).add(row)
+
+		teamResults[row.MatchID] = teamResults[row.MatchID] || row.Result == models.MatchResultWin
+	}
+
+	stats := &TeamStats{
+		TeamID:  teamID,
+		Filter:  filter,
+		Totals:  totals.line(),
+		Maps:    breakdown(maps),
+		Agents:  breakdown(agents),
+		Players: make([]PlayerStats, 0, len(players)),
+		Leaders: make(map[string]*MetricLeaders),
+	}
+
+	stats.TeamMatches = len(teamResults)
+	for _, won := range teamResults {
+		if won {
+			stats.TeamWins++
+		}
+	}
+	stats.TeamWinRate = percent(stats.TeamWins, stats.TeamMatches)
+
+	for _, player := range players {
+		playerStats := PlayerStats{
+			PlayerID:   player.ID,
+			UserID:     player.UserID,
+			GameName:   player.GameName,
+			Tag:        player.Tag,
+			RosterSlot: slots[player.UserID],
+			Lifetime:   player.Stats,
+		}
+		if acc := byPlayer[player.ID]; acc != nil {
+			playerStats.StatLine = acc.line()
+		}
+		stats.Players = append(stats.Players, playerStats)
+	}
+
+	for name, metric := range leaderMetrics {
+		leaders := &MetricLeaders{}
+		for i := range stats.Players {
+			player := &stats.Players[i]
+			if player.Matches == 0 || player.RosterSlot != models.RosterStarter {
+				continue
+			}
+
+			value := PlayerMetric{PlayerID: player.PlayerID, GameName: player.GameName, Value: metric.value(&player.StatLine)}
+			if leaders.Best == nil || better(value.Value, leaders.Best.Value, metric.lowerBetter) {
+				best := value
+				leaders.Best = &best
+			}
+			if leaders.Worst == nil || better(leaders.Worst.Value, value.Value, metric.lowerBetter) {
+				worst := value
+				leaders.Worst = &worst
+			}
+		}
+		stats.Leaders[name] = leaders
+	}
+
+	return stats, nil
+}
+
+// GetOrganizationStats собирает статистику всех команд организации и общие показатели
+func GetOrganizationStats(organizationID uint, filter StatsFilter) (*OrganizationStats, error) {
+	organization, err := GetOrganization(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &OrganizationStats{
+		OrganizationID: organization.ID,
+		Filter:         filter,
+		Teams:          make([]OrganizationTeamStats, 0, len(organization.Teams)),
+	}
+
+	var totals statAccumulator
+	seen := make(map[[2]uint]bool)
+	for _, team := range organization.Teams {
+		teamStats, err := GetTeamStats(team.ID, filter)
+		if err != nil {
+			return nil, err
+		}
+		stats.Teams = append(stats.Teams, OrganizationTeamStats{TeamName: team.Name, TeamStats: teamStats})
+		stats.TeamMatches += teamStats.TeamMatches
+		stats.TeamWins += teamStats.TeamWins
+
+		rows, err := loadTeamMatchRows(team.ID, filter)
+		if err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			key := [2]uint{rows[i].PlayerID, rows[i].MatchID}
+			if rows[i].RosterSlot != models.RosterStarter || seen[key] {
+				continue
+			}
+			seen[key] = true
+			totals.add(&rows[i])
+		}
+	}
+
+	stats.TeamWinRate = percent(stats.TeamWins, stats.TeamMatches)
+	stats.Totals = totals.line()
+	return stats, nil
+}
+
+// playerSlots позиции, матчи которых попадают в статистику команды
+var playerSlots = []string{models.RosterStarter, models.RosterSub}
+
+// loadTeamMatchRows загружает матчи игроков основы и запаса, попадающие под фильтр
+func loadTeamMatchRows(teamID uint, filter StatsFilter) ([]playerMatchRow, error) {
+	query := database.DB.Model(&models.ValorantPlayerMatch{}).
+		Select(`valorant_player_matches.player_id, team_memberships.roster_slot, valorant_player_matches.match_id,
+			valorant_player_matches.result, valorant_player_matches.agent, valorant_matches.map,
+			valorant_player_matches.kills, valorant_player_matches.deaths, valorant_player_matches.assists,
+			valorant_player_matches.score, valorant_player_matches.damage, valorant_player_matches.headshots,
+			valorant_player_matches.bodyshots, valorant_player_matches.legshots`).
+		Joins("JOIN valorant_matches ON valorant_matches.id = valorant_player_matches.match_id AND valorant_matches.deleted_at IS NULL").
+		Joins("JOIN valorant_players ON valorant_players.id = valorant_player_matches.player_id AND valorant_players.deleted_at IS NULL").
+		Joins("JOIN team_memberships ON team_memberships.user_id = valorant_players.user_id").
+		Where("team_memberships.team_id = ? AND team_memberships.roster_slot IN ?", teamID, playerSlots).
+		Where("NOT EXISTS (SELECT 1 FROM team_match_archives WHERE team_match_archives.team_id = ? AND team_match_archives.match_id = valorant_matches.id)", teamID)
+
+	if filter.From != nil {
+		query = query.Where("valorant_matches.date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("valorant_matches.date < ?", *filter.To)
+	}
+	// Режим хранится нормализованным, поэтому "Competitive" и "competitive" равнозначны
+	if mode := normalizeMode(filter.Mode); mode != "" {
+		query = query.Where("valorant_matches.mode = ?", mode)
+	}
+
+	var rows []playerMatchRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func accumulatorFor(groups map[string]*statAccumulator, name string) *statAccumulator {
+	if groups[name] == nil {
+		groups[name] = &statAccumulator{}
+	}
+	return groups[name]
+}
+
+// breakdown превращает группы в список, отсортированный по числу матчей
+func breakdown(groups map[string]*statAccumulator) []BreakdownStats {
+	result := make([]BreakdownStats, 0, len(groups))
+	for name, acc := range groups {
+		result = append(result, BreakdownStats{Name: name, StatLine: acc.line()})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Matches != result[j].Matches {
+			return result[i].Matches > result[j].Matches
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// better сообщает, лучше ли значение a, чем b
+func better(a, b float64, lowerBetter bool) bool {
+	if lowerBetter {
+		return a < b
+	}
+	return a > b
+}
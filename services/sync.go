@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"valorant-app/database"
+	"valorant-app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// syncMatchCount количество последних матчей, запрашиваемых при синхронизации
+const syncMatchCount = 20
+
+// syncMatchWorkers количество одновременных запросов деталей матчей одного аккаунта
+const syncMatchWorkers = 4
+
+// SyncResult итог синхронизации одного аккаунта Valorant
+type SyncResult struct {
+	PlayerID       uint                   `json:"player_id"`
+	NewMatches     int                    `json:"new_matches"`
+	SkippedMatches int                    `json:"skipped_matches"`
+	FailedMatches  int                    `json:"failed_matches"`
+	Player         *models.ValorantPlayer `json:"player"`
+	Error          string                 `json:"error,omitempty"` // Почему аккаунт не удалось синхронизировать (SyncUserPlayers)
+}
+
+// Пауза перед следующей синхронизацией аккаунта после неудачи: удваивается с каждой неудачей подряд
+const (
+	syncBackoffBase = 5 * time.Minute
+	syncBackoffMax  = 24 * time.Hour
+)
+
+// activeSyncs аккаунты, которые сейчас синхронизируются: планировщиком, через REST API или из бота
+var activeSyncs = struct {
+	sync.Mutex
+	players map[uint]bool
+}{players: make(map[uint]bool)}
+
+// claimPlayerSync отмечает аккаунт как синхронизируемый; false — его уже синхронизирует кто-то другой
+func claimPlayerSync(playerID uint) bool {
+	activeSyncs.Lock()
+	defer activeSyncs.Unlock()
+	if activeSyncs.players[playerID] {
+		return false
+	}
+	activeSyncs.players[playerID] = true
+	return true
+}
+
+func releasePlayerSync(playerID uint) {
+	activeSyncs.Lock()
+	defer activeSyncs.Unlock()
+	delete(activeSyncs.players, playerID)
+}
+
+// isPlayerSyncing сообщает, синхронизируется ли аккаунт прямо сейчас
+func isPlayerSyncing(playerID uint) bool {
+	activeSyncs.Lock()
+	defer activeSyncs.Unlock()
+	return activeSyncs.players[playerID]
+}
+
+// SyncPlayer синхронизирует аккаунт с Valorant API: ранг, уровень, новые матчи и статистику.
+// Запросы к API выполняются до начала транзакции, все записи в БД — в одной транзакции.
+// При отмене ctx синхронизация прерывается до записи в БД и возвращает ctx.Err().
+// Успех и ошибка записываются в аккаунт (last_synced_at, last_error); после ошибки
+// планировщик откладывает аккаунт на syncBackoff.
+// Если аккаунт уже синхронизируется, возвращается ErrSyncInProgress, и неудача не записывается.
+func SyncPlayer(ctx context.Context, provider ValorantProvider, player *models.ValorantPlayer) (*SyncResult, error) {
+	if !claimPlayerSync(player.ID) {
+		return nil, ErrSyncInProgress
+	}
+	defer releasePlayerSync(player.ID)
+
+	result, err := syncPlayer(ctx, provider, player)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		if recordErr := recordSyncFailure(player, err); recordErr != nil {
+			log.Printf("Failed to record sync failure of player %d: %v", player.ID, recordErr)
+		}
+	}
+	return result, err
+}
+
+func syncPlayer(ctx context.Context, provider ValorantProvider, player *models.ValorantPlayer) (*SyncResult, error) {
+	account, err := provider.GetAccount(ctx, player.Region, player.GameName, player.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("get account %s#%s: %w", player.GameName, player.Tag, err)
+	}
+
+	mmr, err := provider.GetMMR(ctx, player.Region, account.Puuid)
+	if err != nil {
+		return nil, fmt.Errorf("get rank: %w", err)
+	}
+	rank, rating := mmr.Tier, mmr.RankRating
+
+	matchIDs, err := provider.GetMatchList(ctx, player.Region, account.Puuid, syncMatchCount)
+	if err != nil {
+		return nil, fmt.Errorf("get matches: %w", err)
+	}
+
+	known, err := knownMatchIDs(player.ID, matchIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load known matches: %w", err)
+	}
+
+	result := &SyncResult{PlayerID: player.ID, Player: player}
+
+	// Загружаем детали только тех матчей, которых еще нет у игрока
+	var newIDs []string
+	for _, matchID := range matchIDs {
+		if known[matchID] {
+			result.SkippedMatches++
+			continue
+		}
+		newIDs = append(newIDs, matchID)
+	}
+
+	details, err := fetchMatchDetails(ctx, provider, player, newIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get match details: %w", err)
+	}
+	result.FailedMatches += len(newIDs) - len(details)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		player.LastSyncedAt = &now
+		player.LastError = ""
+		player.SyncFailures = 0
+		player.NextSyncAt = nil
+		player.Puuid = account.Puuid
+		// Не все поставщики сообщают уровень аккаунта
+		if account.AccountLevel > 0 {
+			player.Level = account.AccountLevel
+		}
+		player.Rank = rank
+		player.RankRating = rating
+		if isHigherRank(rank, rating, player.PeakRank, player.PeakRating) {
+			player.PeakRank = rank
+			player.PeakRating = rating
+		}
+
+		if err := tx.Omit(clause.Associations).Save(player).Error; err != nil {
+			return err
+		}
+
+		for _, match := range details {
+			stats := findMatchPlayer(match, account.Puuid)
+			if stats == nil {
+				log.Printf("Player %d not found in match %s", player.ID, match.MatchID)
+				result.FailedMatches++
+				continue
+			}
+
+			created, err := saveMatch(tx, player.ID, match, stats)
+			if err != nil {
+				return err
+			}
+			// Участие уже сохранено, например синхронизацией в другом экземпляре приложения
+			if !created {
+				result.SkippedMatches++
+				continue
+			}
+			result.NewMatches++
+		}
+
+		stats, err := recomputeStats(tx, player.ID)
+		if err != nil {
+			return err
+		}
+		player.Stats = stats
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save sync result: %w", err)
+	}
+
+	return result, nil
+}
+
+// recordSyncFailure записывает ошибку синхронизации и откладывает следующую попытку
+func recordSyncFailure(player *models.ValorantPlayer, syncErr error) error {
+	player.SyncFailures++
+	player.LastError = syncErr.Error()
+	next := time.Now().Add(syncBackoff(player.SyncFailures, syncErr))
+	player.NextSyncAt = &next
+
+	return database.DB.Model(player).Select("sync_failures", "last_error", "next_sync_at").Updates(player).Error
+}
+
+// syncBackoff пауза после failures неудач подряд; Retry-After от API, если он дольше
+func syncBackoff(failures int, syncErr error) time.Duration {
+	backoff := syncBackoffMax
+	if failures < 10 {
+		backoff = min(syncBackoffBase<<(failures-1), syncBackoffMax)
+	}
+
+	var apiErr *ValorantAPIError
+	if errors.As(syncErr, &apiErr) && apiErr.RetryAfter > backoff {
+		backoff = apiErr.RetryAfter
+	}
+	return backoff
+}
+
+// fetchMatchDetails параллельно загружает детали матчей, не более syncMatchWorkers запросов одновременно.
+// Матчи, которые не удалось загрузить, пропускаются; порядок остальных сохраняется.
+// При отмене ctx новые запросы не начинаются, текущие прерываются, и возвращается ctx.Err().
+func fetchMatchDetails(ctx context.Context, provider ValorantProvider, player *models.ValorantPlayer, matchIDs []string) ([]*MatchInfo, error) {
+	fetched := make([]*MatchInfo, len(matchIDs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(syncMatchWorkers, len(matchIDs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				match, err := provider.GetMatchDetails(ctx, player.Region, matchIDs[i])
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Printf("Failed to fetch match %s for player %d: %v", matchIDs[i], player.ID, err)
+					}
+					continue
+				}
+				fetched[i] = match
+			}
+		}()
+	}
+
+feed:
+	for i := range matchIDs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	details := make([]*MatchInfo, 0, len(matchIDs))
+	for _, match := range fetched {
+		if match != nil {
+			details = append(details, match)
+		}
+	}
+	return details, nil
+}
+
+// knownMatchIDs возвращает Riot ID матчей, которые уже сохранены для игрока
+func knownMatchIDs(playerID uint, matchIDs []string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	if len(matchIDs) == 0 {
+		return known, nil
+	}
+
+	var ids []string
+	err := database.DB.Model(&models.ValorantPlayerMatch{}).
+		Joins("JOIN valorant_matches ON valorant_matches.id = valorant_player_matches.match_id").
+		Where("valorant_player_matches.player_id = ? AND valorant_matches.match_id IN ?", playerID, matchIDs).
+		Pluck("valorant_matches.match_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		known[id] = true
+	}
+	return known, nil
+}
+
+// findMatchPlayer находит статистику игрока в деталях матча
+func findMatchPlayer(match *MatchInfo, puuid string) *MatchPlayerInfo {
+	for i := range match.Players {
+		if match.Players[i].Puuid == puuid {
+			return &match.Players[i]
+		}
+	}
+	return nil
+}
+
+// saveMatch создает матч (если его еще нет) и запись об участии игрока.
+// Общий матч могут одновременно сохранять синхронизации нескольких игроков, поэтому вставки не падают
+// на уникальных индексах, а уже существующие записи переиспользуются. false — участие игрока уже было сохранено.
+func saveMatch(tx *gorm.DB, playerID uint, info *MatchInfo, stats *MatchPlayerInfo) (bool, error) {
+	// Результат считается по флагу победы команды игрока: поставщики отдают матч без привязки к игроку
+	playerResult := models.MatchResultLoss
+	if stats.Won {
+		playerResult = models.MatchResultWin
+	}
+
+	match := models.ValorantMatch{
+		MatchID:  info.MatchID,
+		Map:      info.Map,
+		Mode:     info.Mode,
+		Result:   playerResult,
+		Score:    info.Score,
+		Duration: info.Duration,
+		Date:     parseMatchDate(info.Date),
+	}
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "match_id"}}, DoNothing: true}).Create(&match).Error
+	if err != nil {
+		return false, err
+	}
+	// Матч уже сохранен, в том числе другой транзакцией, которая завершилась, пока ждали ее вставку
+	if match.ID == 0 {
+		if err := tx.Where("match_id = ?", info.MatchID).First(&match).Error; err != nil {
+			return false, err
+		}
+	}
+
+	playerMatch := models.ValorantPlayerMatch{
+		MatchID:     match.ID,
+		PlayerID:    playerID,
+		Result:      playerResult,
+		Agent:       stats.Agent,
+		Kills:       stats.Kills,
+		Deaths:      stats.Deaths,
+		Assists:     stats.Assists,
+		Score:       stats.Score,
+		Damage:      stats.Damage,
+		Headshots:   stats.Headshots,
+		Bodyshots:   stats.Bodyshots,
+		Legshots:    stats.Legshots,
+		FirstKills:  stats.FirstKills,
+		FirstDeaths: stats.FirstDeaths,
+	}
+	result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "match_id"}, {Name: "player_id"}}, DoNothing: true}).
+		Create(&playerMatch)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// recomputeStats пересчитывает ValorantStats игрока по всем сохраненным матчам
+func recomputeStats(tx *gorm.DB, playerID uint) (*models.ValorantStats, error) {
+	var agg struct {
+		TotalMatches   int
+		Wins           int
+		AverageScore   float64
+		AverageKills   float64
+		AverageDeaths  float64
+		AverageAssists float64
+		Headshots      int
+		Shots          int
+	}
+
+	err := tx.Model(&models.ValorantPlayerMatch{}).
+		Select(`COUNT(*) AS total_matches,
+			COUNT(*) FILTER (WHERE result = ?) AS wins,
+			COALESCE(AVG(score), 0) AS average_score,
+			COALESCE(AVG(kills), 0) AS average_kills,
+			COALESCE(AVG(deaths), 0) AS average_deaths,
+			COALESCE(AVG(assists), 0) AS average_assists,
+			COALESCE(SUM(headshots) FILTER (WHERE bodyshots + legshots > 0), 0) AS headshots,
+			COALESCE(SUM(headshots + bodyshots + legshots) FILTER (WHERE bodyshots + legshots > 0), 0) AS shots`,
+			models.MatchResultWin).
+		Where("player_id = ?", playerID).
+		Scan(&agg).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stats models.ValorantStats
+	if err := tx.Where(models.ValorantStats{PlayerID: playerID}).FirstOrInit(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	stats.TotalMatches = agg.TotalMatches
+	stats.Wins = agg.Wins
+	stats.Losses = agg.TotalMatches - agg.Wins
+	stats.WinRate = percent(agg.Wins, agg.TotalMatches)
+	stats.AverageScore = agg.AverageScore
+	stats.AverageKills = agg.AverageKills
+	stats.AverageDeaths = agg.AverageDeaths
+	stats.AverageAssists = agg.AverageAssists
+	// Матчи, сохраненные до учета попаданий в тело и ноги, в процент попаданий в голову не входят
+	stats.HeadshotRate = percent(agg.Headshots, agg.Shots)
+
+	if err := tx.Omit(clause.Associations).Save(&stats).Error; err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// isHigherRank сравнивает ранг с текущим пиковым
+func isHigherRank(rank string, rating int, peakRank string, peakRating int) bool {
+	current, peak := models.RankTierIndex(rank), models.RankTierIndex(peakRank)
+	if current != peak {
+		return current > peak
+	}
+	return rating > peakRating
+}
+
+// parseMatchDate разбирает дату матча из API, при ошибке возвращает нулевое время
+func parseMatchDate(value string) time.Time {
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
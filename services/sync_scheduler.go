@@ -0,0 +1,399 @@
+package services
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+	"valorant-app/config"
+	"valorant-app/database"
+	"valorant-app/models"
+
+	"gorm.io/gorm"
+)
+
+// Параметры фоновой синхронизации
+const (
+	syncScanInterval = time.Minute     // Как часто искать устаревшие аккаунты
+	syncScanBatch    = 200             // Сколько аккаунтов добавлять в очередь за один проход
+	syncJobTimeout   = 2 * time.Minute // Предельное время синхронизации одного аккаунта
+)
+
+// ValorantSync общий планировщик синхронизации, создается в StartSyncScheduler
+var ValorantSync *SyncScheduler
+
+// SyncScheduler фоновая синхронизация всех привязанных аккаунтов Valorant.
+// Раз в syncScanInterval аккаунты, которые не синхронизировались дольше interval и не отложены после ошибки,
+// попадают в очередь: сначала принудительные, затем давно не синхронизированные. Очередь разбирают workers воркеров.
+type SyncScheduler struct {
+	provider ValorantProvider
+	interval time.Duration // 0 — только принудительная синхронизация
+	workers  int
+	wake     chan struct{}
+
+	mu       sync.Mutex
+	queue    syncQueue
+	queued   map[uint]*SyncJob
+	running  map[uint]*SyncJob
+	lastScan *time.Time
+	synced   int64
+	failed   int64
+}
+
+// SyncJob аккаунт в очереди или в работе у планировщика
+type SyncJob struct {
+	PlayerID     uint       `json:"player_id"`
+	GameName     string     `json:"game_name"`
+	Tag          string     `json:"tag"`
+	LastSyncedAt *time.Time `json:"last_synced_at"`
+	Forced       bool       `json:"forced"`
+	QueuedAt     time.Time  `json:"queued_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+
+	index int
+}
+
+// SyncBackoff аккаунт, отложенный после неудачной синхронизации
+type SyncBackoff struct {
+	PlayerID     uint       `json:"player_id"`
+	GameName     string     `json:"game_name"`
+	Tag          string     `json:"tag"`
+	SyncFailures int        `json:"sync_failures"`
+	LastError    string     `json:"last_error"`
+	NextSyncAt   *time.Time `json:"next_sync_at"`
+}
+
+// SyncStatus состояние планировщика для администратора
+type SyncStatus struct {
+	IntervalMinutes int           `json:"interval_minutes"`
+	Workers         int           `json:"workers"`
+	LastScanAt      *time.Time    `json:"last_scan_at"`
+	Synced          int64         `json:"synced"` // Успешных синхронизаций с запуска
+	Failed          int64         `json:"failed"` // Неудачных синхронизаций с запуска
+	InProgress      []SyncJob     `json:"in_progress"`
+	Queued          []SyncJob     `json:"queued"` // В порядке обработки
+	BackedOff       []SyncBackoff `json:"backed_off"`
+}
+
+// StartSyncScheduler создает общий планировщик и запускает его до отмены ctx.
+// VALORANT_SYNC_INTERVAL=0 отключает периодическую синхронизацию, принудительная продолжает работать.
+func StartSyncScheduler(ctx context.Context, cfg *config.Config) {
+	ValorantSync = NewSyncScheduler(ValorantClient, time.Duration(cfg.SyncInterval)*time.Minute, cfg.SyncWorkers)
+	ValorantSync.Start(ctx)
+
+	if cfg.SyncInterval > 0 {
+		log.Printf("Valorant sync scheduler started: every %d min, %d workers", cfg.SyncInterval, ValorantSync.workers)
+	} else {
+		log.Println("Periodic Valorant sync is disabled, only forced syncs will run")
+	}
+}
+
+// NewSyncScheduler создает планировщик; аккаунты синхронизируются не реже раза в interval
+func NewSyncScheduler(provider ValorantProvider, interval time.Duration, workers int) *SyncScheduler {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &SyncScheduler{
+		provider: provider,
+		interval: interval,
+		workers:  workers,
+		wake:     make(chan struct{}, 1),
+		queued:   make(map[uint]*SyncJob),
+		running:  make(map[uint]*SyncJob),
+	}
+}
+
+// Start запускает поиск устаревших аккаунтов и воркеры; все останавливаются при отмене ctx
+func (s *SyncScheduler) Start(ctx context.Context) {
+	if s.interval > 0 {
+		go s.scanLoop(ctx)
+	}
+	for i := 0; i < s.workers; i++ {
+		go s.worker(ctx)
+	}
+}
+
+func (s *SyncScheduler) scanLoop(ctx context.Context) {
+	ticker := time.NewTicker(syncScanInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.scan(); err != nil {
+			log.Printf("Failed to scan Valorant accounts for sync: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scan добавляет в очередь аккаунты, которые пора синхронизировать
+func (s *SyncScheduler) scan() error {
+	now := time.Now()
+
+	var players []models.ValorantPlayer
+	err := database.DB.
+		Where("last_synced_at IS NULL OR last_synced_at < ?", now.Add(-s.interval)).
+		Where("next_sync_at IS NULL OR next_sync_at <= ?", now).
+		Order("last_synced_at ASC NULLS FIRST").
+		Limit(syncScanBatch).
+		Find(&players).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range players {
+		s.enqueue(&players[i], false)
+	}
+
+	s.mu.Lock()
+	s.lastScan = &now
+	s.mu.Unlock()
+	return nil
+}
+
+// enqueue добавляет аккаунт в очередь; принудительная синхронизация поднимает уже стоящий в очереди аккаунт.
+// Возвращает false, если аккаунт уже синхронизируется воркером планировщика.
+func (s *SyncScheduler) enqueue(player *models.ValorantPlayer, forced bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.running[player.ID]; ok {
+		return false
+	}
+	if job, ok := s.queued[player.ID]; ok {
+		if forced && !job.Forced {
+			job.Forced = true
+			heap.Fix(&s.queue, job.index)
+		}
+		return true
+	}
+
+	job := &SyncJob{
+		PlayerID:     player.ID,
+		GameName:     player.GameName,
+		Tag:          player.Tag,
+		LastSyncedAt: player.LastSyncedAt,
+		Forced:       forced,
+		QueuedAt:     time.Now(),
+	}
+	heap.Push(&s.queue, job)
+	s.queued[player.ID] = job
+	s.signal()
+	return true
+}
+
+// signal будит один свободный воркер
+func (s *SyncScheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *SyncScheduler) worker(ctx context.Context) {
+	for {
+		job := s.next(ctx)
+		if job == nil {
+			return
+		}
+		s.run(ctx, job)
+	}
+}
+
+// next ждет и забирает аккаунт с наивысшим приоритетом; nil — ctx отменен
+func (s *SyncScheduler) next(ctx context.Context) *SyncJob {
+	for {
+		s.mu.Lock()
+		if s.queue.Len() > 0 {
+			job := heap.Pop(&s.queue).(*SyncJob)
+			delete(s.queued, job.PlayerID)
+			now := time.Now()
+			job.StartedAt = &now
+			s.running[job.PlayerID] = job
+			more := s.queue.Len() > 0
+			s.mu.Unlock()
+
+			// Сигнал один на всех, поэтому будим следующий воркер, пока очередь не пуста
+			if more {
+				s.signal()
+			}
+			return job
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.wake:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// run синхронизирует аккаунт; результат и ошибку записывает SyncPlayer
+func (s *SyncScheduler) run(ctx context.Context, job *SyncJob) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, job.PlayerID)
+		s.mu.Unlock()
+	}()
+
+	var player models.ValorantPlayer
+	if err := database.DB.First(&player, job.PlayerID).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Failed to load Valorant player %d for sync: %v", job.PlayerID, err)
+		}
+		return
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, syncJobTimeout)
+	defer cancel()
+	if job.Forced {
+		jobCtx = WithCacheRefresh(jobCtx)
+	}
+
+	_, err := SyncPlayer(jobCtx, s.provider, &player)
+	// Аккаунт уже синхронизируют через REST API или бота
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrSyncInProgress) {
+		return
+	}
+
+	s.mu.Lock()
+	if err != nil {
+		s.failed++
+	} else {
+		s.synced++
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Background sync of Valorant player %d failed (%d in a row): %v", player.ID, player.SyncFailures, err)
+	}
+}
+
+// Force ставит аккаунт в начало очереди и снимает паузу после ошибок
+func (s *SyncScheduler) Force(playerID uint) error {
+	var player models.ValorantPlayer
+	err := database.DB.First(&player, playerID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPlayerNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Model(&player).Update("next_sync_at", nil).Error; err != nil {
+		return err
+	}
+	if isPlayerSyncing(player.ID) || !s.enqueue(&player, true) {
+		return ErrSyncInProgress
+	}
+	return nil
+}
+
+// ForceAll ставит в очередь все привязанные аккаунты и снимает паузы после ошибок.
+// Возвращает количество поставленных в очередь аккаунтов.
+func (s *SyncScheduler) ForceAll() (int, error) {
+	if err := database.DB.Model(&models.ValorantPlayer{}).Where("next_sync_at IS NOT NULL").Update("next_sync_at", nil).Error; err != nil {
+		return 0, err
+	}
+
+	var players []models.ValorantPlayer
+	if err := database.DB.Order("last_synced_at ASC NULLS FIRST").Find(&players).Error; err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range players {
+		if s.enqueue(&players[i], true) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Status возвращает очередь, аккаунты в работе и отложенные после ошибок
+func (s *SyncScheduler) Status() (*SyncStatus, error) {
+	var backedOff []SyncBackoff
+	err := database.DB.Model(&models.ValorantPlayer{}).
+		Select("id AS player_id, game_name, tag, sync_failures, last_error, next_sync_at").
+		Where("next_sync_at > ?", time.Now()).
+		Order("next_sync_at").
+		Scan(&backedOff).Error
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &SyncStatus{
+		IntervalMinutes: int(s.interval / time.Minute),
+		Workers:         s.workers,
+		LastScanAt:      s.lastScan,
+		Synced:          s.synced,
+		Failed:          s.failed,
+		InProgress:      make([]SyncJob, 0, len(s.running)),
+		Queued:          make([]SyncJob, 0, s.queue.Len()),
+		BackedOff:       backedOff,
+	}
+	for _, job := range s.running {
+		status.InProgress = append(status.InProgress, *job)
+	}
+	sort.Slice(status.InProgress, func(i, j int) bool {
+		return status.InProgress[i].StartedAt.Before(*status.InProgress[j].StartedAt)
+	})
+
+	queued := make(syncQueue, len(s.queue))
+	copy(queued, s.queue)
+	sort.Slice(queued, queued.Less)
+	for _, job := range queued {
+		status.Queued = append(status.Queued, *job)
+	}
+	return status, nil
+}
+
+// syncQueue очередь с приоритетом для container/heap: принудительные аккаунты первыми,
+// затем никогда не синхронизированные, затем по давности последней синхронизации
+type syncQueue []*SyncJob
+
+func (q syncQueue) Len() int { return len(q) }
+
+func (q syncQueue) Less(i, j int) bool {
+	a, b := q[i], q[j]
+	if a.Forced != b.Forced {
+		return a.Forced
+	}
+	if (a.LastSyncedAt == nil) != (b.LastSyncedAt == nil) {
+		return a.LastSyncedAt == nil
+	}
+	if a.LastSyncedAt != nil && !a.LastSyncedAt.Equal(*b.LastSyncedAt) {
+		return a.LastSyncedAt.Before(*b.LastSyncedAt)
+	}
+	return a.QueuedAt.Before(b.QueuedAt)
+}
+
+func (q syncQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *syncQueue) Push(x interface{}) {
+	job := x.(*SyncJob)
+	job.index = len(*q)
+	*q = append(*q, job)
+}
+
+func (q *syncQueue) Pop() interface{} {
+	old := *q
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return job
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"valorant-app/database"
+	"valorant-app/models"
+	"valorant-app/services/valorantfake"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB подключается к PostgreSQL из TEST_DB_DSN и подменяет database.DB транзакцией,
+// которая откатывается после теста. Без TEST_DB_DSN тест пропускается.
+func openTestDB(t *testing.T) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("begin transaction: %v", tx.Error)
+	}
+	previous := database.DB
+	database.DB = tx
+	t.Cleanup(func() {
+		tx.Rollback()
+		database.DB = previous
+	})
+}
+
+// newFakeRiotProvider запускает фейковый Riot API на фикстурах по умолчанию.
+// Кэш не сохраняет матчи в БД: детали матчей загружаются параллельно, а транзакция теста одна.
+func newFakeRiotProvider(t *testing.T) *RiotProvider {
+	t.Helper()
+	return newRiotProviderFor(t, newFakeRiotServer(t))
+}
+
+// newFakeRiotServer фейковый Riot API на фикстурах по умолчанию
+func newFakeRiotServer(t *testing.T) http.Handler {
+	t.Helper()
+
+	fixtures, err := fs.Sub(valorantfake.Fixtures, "fixtures")
+	if err != nil {
+		t.Fatalf("open fixtures: %v", err)
+	}
+	server, err := valorantfake.NewServer(fixtures)
+	if err != nil {
+		t.Fatalf("start fake Valorant API: %v", err)
+	}
+	return server
+}
+
+// newRiotProviderFor создает поставщика Riot API, который обращается к handler
+func newRiotProviderFor(t *testing.T, handler http.Handler) *RiotProvider {
+	t.Helper()
+
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+	return NewRiotProvider("", httpServer.URL, NewRateLimiter(""), NewResponseCache(0, DefaultCacheTTL, false))
+}
+
+func TestSyncPlayerSavesFixtureMatches(t *testing.T) {
+	openTestDB(t)
+	provider := newFakeRiotProvider(t)
+
+	user := models.User{TelegramID: -1, Username: "sync_test"}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	player, err := LinkValorantPlayer(&user, "Aurora", "EU1", "eu")
+	if err != nil {
+		t.Fatalf("link player: %v", err)
+	}
+
+	result, err := SyncPlayer(context.Background(), provider, player)
+	if err != nil {
+		t.Fatalf("SyncPlayer: %v", err)
+	}
+	if result.NewMatches != 3 || result.SkippedMatches != 0 || result.FailedMatches != 0 {
+		t.Errorf("result = %d new, %d skipped, %d failed; want 3, 0, 0",
+			result.NewMatches, result.SkippedMatches, result.FailedMatches)
+	}
+
+	if player.Puuid != "f1b21321-d03d-5261-8537-23ba8531d5f4" {
+		t.Errorf("puuid = %q", player.Puuid)
+	}
+	if player.Rank != "Gold 3" || player.PeakRank != "Gold 3" {
+		t.Errorf("rank = %q, peak = %q; want Gold 3", player.Rank, player.PeakRank)
+	}
+	if player.LastSyncedAt == nil || player.LastError != "" || player.SyncFailures != 0 {
+		t.Errorf("sync state = %v, %q, %d", player.LastSyncedAt, player.LastError, player.SyncFailures)
+	}
+
+	var matches []models.ValorantPlayerMatch
+	err = database.DB.Preload("Match").Where("player_id = ?", player.ID).Find(&matches).Error
+	if err != nil {
+		t.Fatalf("load matches: %v", err)
+	}
+	want := map[string]struct {
+		Map, Mode, Result, Agent string
+		Kills, Deaths, Assists   int
+		Score                    int
+	}{
+		"6f0b1b6e-8d3a-4f55-9c61-0a1f2b3c4d01": {"Ascent", "competitive", models.MatchResultWin, "Jett", 24, 15, 4, 5210},
+		"6f0b1b6e-8d3a-4f55-9c61-0a1f2b3c4d02": {"Bind", "unrated", models.MatchResultLoss, "Raze", 19, 17, 5, 4100},
+		"6f0b1b6e-8d3a-4f55-9c61-0a1f2b3c4d03": {"Haven", "competitive", models.MatchResultWin, "Jett", 22, 18, 6, 4800},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("saved %d matches, want %d", len(matches), len(want))
+	}
+	for _, match := range matches {
+		expected, ok := want[match.Match.MatchID]
+		if !ok {
+			t.Errorf("unexpected match %s", match.Match.MatchID)
+			continue
+		}
+		if match.Match.Map != expected.Map || match.Match.Mode != expected.Mode || match.Result != expected.Result ||
+			match.Agent != expected.Agent || match.Kills != expected.Kills || match.Deaths != expected.Deaths ||
+			match.Assists != expected.Assists || match.Score != expected.Score {
+			t.Errorf("match %s = %s/%s %s %s %d/%d/%d %d, want %+v", match.Match.MatchID,
+				match.Match.Map, match.Match.Mode, match.Result, match.Agent,
+				match.Kills, match.Deaths, match.Assists, match.Score, expected)
+		}
+	}
+
+	stats := player.Stats
+	if stats == nil {
+		t.Fatal("stats were not computed")
+	}
+	if stats.TotalMatches != 3 || stats.Wins != 2 || stats.Losses != 1 {
+		t.Errorf("stats = %d matches, %d wins, %d losses; want 3, 2, 1", stats.TotalMatches, stats.Wins, stats.Losses)
+	}
+	if !almostEqual(stats.AverageKills, 65.0/3) || !almostEqual(stats.AverageDeaths, 50.0/3) ||
+		!almostEqual(stats.AverageAssists, 5) || !almostEqual(stats.AverageScore, 14110.0/3) {
+		t.Errorf("averages = %.2f/%.2f/%.2f, score %.2f", stats.AverageKills, stats.AverageDeaths,
+			stats.AverageAssists, stats.AverageScore)
+	}
+
+	// Повторная синхронизация не загружает уже сохраненные матчи
+	result, err = SyncPlayer(context.Background(), provider, player)
+	if err != nil {
+		t.Fatalf("second SyncPlayer: %v", err)
+	}
+	if result.NewMatches != 0 || result.SkippedMatches != 3 {
+		t.Errorf("second sync = %d new, %d skipped; want 0, 3", result.NewMatches, result.SkippedMatches)
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
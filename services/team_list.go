@@ -0,0 +1,266 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"valorant-app/database"
+	"valorant-app/models"
+)
+
+// Варианты сортировки списка команд
+const (
+	TeamSortCreated = "created"
+	TeamSortName    = "name"
+	TeamSortMembers = "members"
+	TeamSortRank    = "rank"
+)
+
+// Ограничения размера страницы списка команд
+const (
+	DefaultTeamsLimit = 20
+	MaxTeamsLimit     = 100
+)
+
+// teamSortColumns выражения сортировки списка команд; ключ курсора — значение выражения и ID команды
+var teamSortColumns = map[string]string{
+	TeamSortCreated: "teams.id",
+	TeamSortName:    "teams.name",
+	TeamSortMembers: "COALESCE(members.member_count, 0)",
+	TeamSortRank:    "COALESCE(ranks.average_rank, -1)",
+}
+
+// IsTeamSort проверяет, что сортировка списка команд поддерживается
+func IsTeamSort(sort string) bool {
+	_, ok := teamSortColumns[sort]
+	return ok
+}
+
+// TeamFilter фильтры, сортировка и курсор списка команд; nil — фильтр не задан
+type TeamFilter struct {
+	Search         string // Подстрока названия без учета регистра
+	Region         string
+	Recruiting     *bool
+	MinRank        *int // Индексы в models.RankTiers для среднего ранга игроков
+	MaxRank        *int
+	MinMembers     *int
+	MaxMembers     *int
+	Sort           string // TeamSortCreated по умолчанию
+	Descending     bool
+	Cursor         string // Курсор из предыдущей страницы
+	Limit          int
+	IncludeMembers bool // Загружать участников команд
+}
+
+// TeamListItem команда в списке со сводными показателями
+type TeamListItem struct {
+	models.Team
+	MemberCount     int      `json:"member_count"`
+	AverageRank     *float64 `json:"average_rank"` // Средний индекс ранга привязанных аккаунтов в models.RankTiers
+	AverageRankTier string   `json:"average_rank_tier,omitempty"`
+}
+
+// teamListRow строка выборки списка до загрузки самих команд
+type teamListRow struct {
+	ID          uint
+	Name        string
+	MemberCount int
+	AverageRank *float64
+}
+
+// teamCursor содержимое курсора: сортировка, значение ключа сортировки и ID последней команды
+type teamCursor struct {
+	Sort       string      `json:"s"`
+	Descending bool        `json:"d,omitempty"`
+	Value      interface{} `json:"v,omitempty"`
+	ID         uint        `json:"id"`
+}
+
+func (c teamCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTeamCursor(value string) (*teamCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor teamCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// TeamsAfter курсор списка команд по дате создания, указывающий на команду teamID
+func TeamsAfter(teamID uint) string {
+	return teamCursor{Sort: TeamSortCreated, ID: teamID}.encode()
+}
+
+// ListTeams возвращает страницу списка команд и курсор следующей страницы (пустой — страниц больше нет)
+func ListTeams(filter TeamFilter) ([]TeamListItem, string, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultTeamsLimit
+	}
+	if limit > MaxTeamsLimit {
+		limit = MaxTeamsLimit
+	}
+	sort := filter.Sort
+	if sort == "" {
+		sort = TeamSortCreated
+	}
+	sortColumn, ok := teamSortColumns[sort]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown team sort %q", sort)
+	}
+
+	rankTier := rankTierSQL("players.rank")
+	memberCounts := database.DB.Model(&models.TeamMembership{}).
+		Select("team_id, COUNT(*) AS member_count").Group("team_id")
+	ranks := database.DB.Table("team_memberships").
+		Select("team_memberships.team_id, AVG(" + rankTier + ") AS average_rank").
+		Joins("JOIN valorant_players players ON players.user_id = team_memberships.user_id AND players.deleted_at IS NULL").
+		Where(rankTier + " > 0").
+		Group("team_memberships.team_id")
+
+	query := database.DB.Model(&models.Team{}).
+		Select("teams.id, teams.name, COALESCE(members.member_count, 0) AS member_count, ranks.average_rank").
+		Joins("LEFT JOIN (?) AS members ON members.team_id = teams.id", memberCounts).
+		Joins("LEFT JOIN (?) AS ranks ON ranks.team_id = teams.id", ranks)
+
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		// Подстрочный поиск по названию использует триграммный индекс idx_teams_name_trgm
+		query = query.Where("teams.name ILIKE ?", "%"+escapeLike(search)+"%")
+	}
+	if filter.Region != "" {
+		query = query.Where("teams.region = ?", filter.Region)
+	}
+	if filter.Recruiting != nil {
+		query = query.Where("teams.recruiting = ?", *filter.Recruiting)
+	}
+	if filter.MinRank != nil {
+		query = query.Where("ranks.average_rank >= ?", *filter.MinRank)
+	}
+	if filter.MaxRank != nil {
+		query = query.Where("ranks.average_rank <= ?", *filter.MaxRank)
+	}
+	if filter.MinMembers != nil {
+		query = query.Where("COALESCE(members.member_count, 0) >= ?", *filter.MinMembers)
+	}
+	if filter.MaxMembers != nil {
+		query = query.Where("COALESCE(members.member_count, 0) <= ?", *filter.MaxMembers)
+	}
+
+	comparison, direction := ">", "ASC"
+	if filter.Descending {
+		comparison, direction = "<", "DESC"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeTeamCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if cursor.Sort != sort || cursor.Descending != filter.Descending {
+			return nil, "", ErrInvalidCursor
+		}
+		if sort == TeamSortCreated {
+			query = query.Where("teams.id "+comparison+" ?", cursor.ID)
+		} else {
+			query = query.Where("("+sortColumn+", teams.id) "+comparison+" (?, ?)", cursor.Value, cursor.ID)
+		}
+	}
+	if sort != TeamSortCreated {
+		query = query.Order(sortColumn + " " + direction)
+	}
+	query = query.Order("teams.id " + direction)
+
+	// Запрашиваем на одну команду больше, чтобы узнать, есть ли следующая страница
+	var rows []teamListRow
+	if err := query.Limit(limit + 1).Scan(&rows).Error; err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		cursor := teamCursor{Sort: sort, Descending: filter.Descending, ID: last.ID}
+		switch sort {
+		case TeamSortName:
+			cursor.Value = last.Name
+		case TeamSortMembers:
+			cursor.Value = last.MemberCount
+		case TeamSortRank:
+			cursor.Value = -1.0
+			if last.AverageRank != nil {
+				cursor.Value = *last.AverageRank
+			}
+		}
+		next = cursor.encode()
+	}
+
+	items, err := loadTeamListItems(rows, filter.IncludeMembers)
+	if err != nil {
+		return nil, "", err
+	}
+	return items, next, nil
+}
+
+// loadTeamListItems загружает команды страницы, сохраняя порядок выборки
+func loadTeamListItems(rows []teamListRow, includeMembers bool) ([]TeamListItem, error) {
+	items := make([]TeamListItem, 0, len(rows))
+	if len(rows) == 0 {
+		return items, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	query := database.DB
+	if includeMembers {
+		query = query.Preload("Members")
+	}
+	var teams []models.Team
+	if err := query.Where("id IN ?", ids).Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Team, len(teams))
+	for _, team := range teams {
+		byID[team.ID] = team
+	}
+
+	for _, row := range rows {
+		team, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		item := TeamListItem{Team: team, MemberCount: row.MemberCount, AverageRank: row.AverageRank}
+		if row.AverageRank != nil {
+			item.AverageRankTier = models.RankTiers[int(math.Round(*row.AverageRank))]
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// rankTierSQL выражение индекса ранга column в models.RankTiers; неизвестный ранг — NULL
+func rankTierSQL(column string) string {
+	var expression strings.Builder
+	expression.WriteString("CASE LOWER(" + column + ")")
+	for i, tier := range models.RankTiers {
+		fmt.Fprintf(&expression, " WHEN '%s' THEN %d", strings.ToLower(tier), i)
+	}
+	expression.WriteString(" END")
+	return expression.String()
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"valorant-app/database"
+	"valorant-app/database/seeders"
+	"valorant-app/models"
+	"valorant-app/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetTeam возвращает команду вместе с участниками
+func GetTeam(teamID uint) (*models.Team, error) {
+	var team models.Team
+	result := database.DB.Preload("Members").First(&team, teamID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrTeamNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &team, nil
+}
+
+// CreateTeam создает команду с набором ролей из каталога шаблонов и делает создателя владельцем
+func CreateTeam(user *models.User, name, description string) (*models.Team, error) {
+	team := models.Team{
+		Name:        name,
+		Description: description,
+		CreatedBy:   user.ID,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&team).Error; err != nil {
+			return err
+		}
+
+		// Команда получает полный набор ролей по шаблонам
+		if err := seeders.SeedTeamRoles(tx, team.ID); err != nil {
+			return err
+		}
+
+		var ownerRole models.Role
+		if err := tx.Where("name = ? AND team_id = ?", models.RoleOwner, team.ID).First(&ownerRole).Error; err != nil {
+			return err
+		}
+
+		// Создатель становится участником основного состава с ролью владельца
+		if err := createMembership(tx, user, team.ID, models.RosterStarter); err != nil {
+			return err
+		}
+		if err := tx.Model(user).Association("Roles").Append(&ownerRole); err != nil {
+			return err
+		}
+		return recordAudit(tx, user, team.ID, models.AuditTeamCreated, models.AuditTargetTeam, team.ID, nil, snapshotTeam(&team))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// TeamUpdate изменяемые поля команды; nil — оставить без изменений
+type TeamUpdate struct {
+	Name        *string
+	Description *string
+	Region      *string // Пустая строка снимает регион
+	Recruiting  *bool
+}
+
+// UpdateTeam изменяет название, описание, регион и статус набора команды
+func UpdateTeam(actor *models.User, teamID uint, update TeamUpdate) (*models.Team, error) {
+	if update.Region != nil && *update.Region != "" && !models.IsValorantRegion(*update.Region) {
+		return nil, ErrInvalidRegion
+	}
+
+	var team models.Team
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, teamID)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		before := snapshotTeam(&team)
+
+		if update.Name != nil {
+			team.Name = *update.Name
+		}
+		if update.Description != nil {
+			team.Description = *update.Description
+		}
+		if update.Region != nil {
+			team.Region = *update.Region
+		}
+		if update.Recruiting != nil {
+			team.Recruiting = *update.Recruiting
+		}
+
+		err := tx.Model(&team).Select("name", "description", "region", "recruiting").Updates(&team).Error
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, teamID, models.AuditTeamUpdated, models.AuditTargetTeam, teamID, before, snapshotTeam(&team))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// addTeamMember добавляет пользователя в команду и назначает ему роль участника.
+// Вызывается внутри транзакции при использовании приглашения.
+func addTeamMember(tx *gorm.DB, user *models.User, team *models.Team) error {
+	// Новый участник начинает в запасе; состав меняется через UpdateRosterEntry
+	if err := createMembership(tx, user, team.ID, models.RosterSub); err != nil {
+		return err
+	}
+
+	memberRole, err := teamMemberRole(tx, team.ID)
+	if err != nil || memberRole == nil {
+		return err
+	}
+
+	// Назначаем роль участника
+	return tx.Model(user).Association("Roles").Append(memberRole)
+}
+
+// createMembership записывает членство пользователя в команде на позиции slot с учетом лимита состава.
+// Первая команда пользователя становится выбранной в боте.
+func createMembership(tx *gorm.DB, user *models.User, teamID uint, slot string) error {
+	var team models.Team
+	if err := tx.Select("id", "roster_limit").First(&team, teamID).Error; err != nil {
+		return err
+	}
+	if team.RosterLimit > 0 {
+		var members int64
+		if err := tx.Model(&models.TeamMembership{}).Where("team_id = ?", teamID).Count(&members).Error; err != nil {
+			return err
+		}
+		if members >= int64(team.RosterLimit) {
+			return ErrTeamFull
+		}
+	}
+
+	membership := models.TeamMembership{
+		TeamID:     teamID,
+		UserID:     user.ID,
+		Status:     models.MembershipActive,
+		RosterSlot: slot,
+		JoinedAt:   time.Now(),
+	}
+	if err := tx.Create(&membership).Error; err != nil {
+		return err
+	}
+
+	if user.CurrentTeamID != nil {
+		return nil
+	}
+	user.CurrentTeamID = &teamID
+	return tx.Model(user).Update("current_team_id", teamID).Error
+}
+
+// teamMemberRole находит роль участника команды; nil — команда удалила или переименовала эту роль.
+// Роли создаются по шаблонам при создании команды, здесь они не пересоздаются.
+func teamMemberRole(tx *gorm.DB, teamID uint) (*models.Role, error) {
+	var memberRole models.Role
+	result := tx.Where("name = ? AND team_id = ?", models.RoleMember, teamID).First(&memberRole)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &memberRole, nil
+}
+
+// removeTeamMember убирает пользователя из команды вместе с ролями этой команды
+func removeTeamMember(tx *gorm.DB, user *models.User, teamID uint) error {
+	err := tx.Exec("DELETE FROM user_roles WHERE user_id = ? AND role_id IN (?)",
+		user.ID, tx.Model(&models.Role{}).Select("id").Where("team_id = ?", teamID)).Error
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Where("team_id = ? AND user_id = ?", teamID, user.ID).Delete(&models.TeamMembership{}).Error; err != nil {
+		return err
+	}
+
+	// Выбранной в боте команда быть перестает
+	if user.CurrentTeamID != nil && *user.CurrentTeamID == teamID {
+		user.CurrentTeamID = nil
+	}
+	return tx.Model(&models.User{}).Where("id = ? AND current_team_id = ?", user.ID, teamID).
+		Update("current_team_id", nil).Error
+}
+
+// LeaveTeam убирает пользователя из команды вместе с ролями этой команды.
+// Владелец должен сначала передать команду или распустить ее.
+func LeaveTeam(user *models.User, teamID uint) error {
+	if !utils.IsTeamMember(user.ID, teamID) {
+		return ErrNotInTeam
+	}
+	if utils.IsTeamOwner(user.ID, teamID) {
+		return ErrOwnerCannotLeave
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := removeTeamMember(tx, user, teamID); err != nil {
+			return err
+		}
+		return recordAudit(tx, user, teamID, models.AuditMemberLeft, models.AuditTargetUser, user.ID, nil, nil)
+	})
+}
+
+// archiveTeamMatches записывает матчи игроков основы и замены команды в ее архив.
+// Общие записи ValorantMatch не меняются: тот же матч может учитываться в статистике других команд.
+func archiveTeamMatches(tx *gorm.DB, teamID uint, archivedAt time.Time) error {
+	return tx.Exec(`INSERT INTO team_match_archives (team_id, match_id, archived_at)
+		SELECT DISTINCT ?::bigint, valorant_player_matches.match_id, ?::timestamptz
+		FROM valorant_player_matches
+		JOIN valorant_players ON valorant_players.id = valorant_player_matches.player_id AND valorant_players.deleted_at IS NULL
+		JOIN team_memberships ON team_memberships.user_id = valorant_players.user_id
+		WHERE team_memberships.team_id = ? AND team_memberships.roster_slot IN ? AND valorant_player_matches.deleted_at IS NULL
+		ON CONFLICT DO NOTHING`, teamID, archivedAt, teamID, playerSlots).Error
+}
+
+// DisbandTeam распускает команду: участники освобождаются, приглашения отзываются,
+// ожидающие заявки отклоняются, матчи архивируются, а сама команда удаляется (soft delete).
+func DisbandTeam(owner *models.User, teamID uint) (*models.Team, error) {
+	var team models.Team
+	var members []models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, teamID)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if team.CreatedBy != owner.ID {
+			return ErrNotTeamOwner
+		}
+
+		if err := tx.Model(&team).Association("Members").Find(&members); err != nil {
+			return err
+		}
+
+		// Архивируем матчи, учтенные в статистике команды, пока известен ее состав
+		if err := archiveTeamMatches(tx, teamID, time.Now()); err != nil {
+			return err
+		}
+
+		// Снимаем роли команды до удаления самих ролей
+		err := tx.Exec("DELETE FROM user_roles WHERE role_id IN (?)",
+			tx.Model(&models.Role{}).Select("id").Where("team_id = ?", teamID)).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamMembership{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("current_team_id = ?", teamID).Update("current_team_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.Role{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.RoleTemplate{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		err = tx.Model(&models.Invitation{}).
+			Where("team_id = ? AND revoked_at IS NULL", teamID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.JoinRequest{}).
+			Where("team_id = ? AND status = ?", teamID, models.JoinRequestPending).
+			Updates(map[string]interface{}{"status": models.JoinRequestRejected, "reviewer_id": owner.ID, "reviewed_at": now}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&team).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, owner, teamID, models.AuditTeamDisbanded, models.AuditTargetTeam, teamID, snapshotTeam(&team), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notify(func(n Notifier) { n.NotifyTeamDisbanded(&team, members) })
+	return &team, nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"valorant-app/database"
+	"valorant-app/models"
+
+	"gorm.io/gorm"
+)
+
+// RegisterUser находит пользователя по Telegram ID или создает нового.
+// Username и язык обновляются, если изменились в Telegram: по username участников находят в боте.
+// Имя берется из Telegram только при регистрации, дальше его меняет сам пользователь через PUT /api/me.
+func RegisterUser(telegramID int64, username, firstName, lastName, languageCode string) (*models.User, error) {
+	var user models.User
+	result := database.DB.Where("telegram_id = ?", telegramID).First(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		user = models.User{
+			TelegramID:   telegramID,
+			Username:     username,
+			FirstName:    firstName,
+			LastName:     lastName,
+			LanguageCode: languageCode,
+		}
+		if err := database.DB.Create(&user).Error; err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if user.Username != username || user.LanguageCode != languageCode {
+		user.Username = username
+		user.LanguageCode = languageCode
+		err := database.DB.Model(&user).Updates(map[string]interface{}{
+			"username":      username,
+			"language_code": languageCode,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+// FindUserByTelegramID находит зарегистрированного пользователя по Telegram ID
+func FindUserByTelegramID(telegramID int64) (*models.User, error) {
+	var user models.User
+	result := database.DB.Where("telegram_id = ?", telegramID).First(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// FindTeamMemberByUsername находит участника команды по Telegram username
+func FindTeamMemberByUsername(teamID uint, username string) (*models.User, error) {
+	var user models.User
+	result := database.DB.Where("id IN (?) AND LOWER(username) = LOWER(?)", teamMemberIDs(teamID), username).First(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotInTeam
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
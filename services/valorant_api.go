@@ -1,210 +1,358 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	"valorant-app/config"
+	"valorant-app/services/valorantfake"
 )
 
-// ValorantAPIClient клиент для работы с Valorant API
+// ValorantProvider источник данных Valorant: аккаунты, ранг, списки матчей и детали матчей.
+// Реализации: RiotProvider (официальный API), HenrikProvider (community API) и фейковый сервер из valorantfake.
+// Все методы прерываются при отмене ctx и возвращают ctx.Err().
+type ValorantProvider interface {
+	// GetAccount находит аккаунт по Riot ID в регионе region
+	GetAccount(ctx context.Context, region, gameName, tag string) (*PlayerInfo, error)
+	// GetMMR возвращает текущий ранг аккаунта
+	GetMMR(ctx context.Context, region, puuid string) (*MMRInfo, error)
+	// GetMatchList возвращает ID последних count матчей аккаунта, от новых к старым
+	GetMatchList(ctx context.Context, region, puuid string, count int) ([]string, error)
+	// GetMatchDetails возвращает детали матча
+	GetMatchDetails(ctx context.Context, region, matchID string) (*MatchInfo, error)
+}
+
+// Поставщики данных Valorant, выбираемые через VALORANT_PROVIDER
+const (
+	ProviderRiot   = "riot"
+	ProviderHenrik = "henrik"
+	ProviderFake   = "fake"
+)
+
+// Повторы запросов к Valorant API при 429, 5xx и сетевых ошибках
+const (
+	valorantMaxRetries   = 3
+	valorantRetryBackoff = 500 * time.Millisecond
+)
+
+// ValorantAPIClient HTTP-клиент для JSON API Valorant, общий для всех поставщиков.
+// Ответы берутся из общего кэша, если он задан; запросы к API проходят через общий лимитер,
+// а 429, 5xx и сетевые ошибки повторяются с экспоненциальной паузой.
 type ValorantAPIClient struct {
-	BaseURL    string
 	APIKey     string
+	AuthHeader string         // Заголовок с ключом API: X-Riot-Token у Riot, Authorization у HenrikDev
+	Limiter    *RateLimiter   // nil — без ограничения частоты
+	Cache      *ResponseCache // nil — без кэша
+	MaxRetries int
 	HTTPClient *http.Client
 }
 
 // NewValorantAPIClient создает новый клиент
-func NewValorantAPIClient(apiKey string) *ValorantAPIClient {
+func NewValorantAPIClient(apiKey, authHeader string, limiter *RateLimiter, cache *ResponseCache) *ValorantAPIClient {
 	return &ValorantAPIClient{
-		BaseURL: "https://pd.riotgames.com", // или другой базовый URL
-		APIKey:  apiKey,
+		APIKey:     apiKey,
+		AuthHeader: authHeader,
+		Limiter:    limiter,
+		Cache:      cache,
+		MaxRetries: valorantMaxRetries,
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
-// PlayerInfo информация об игроке
-type PlayerInfo struct {
-	Puuid    string `json:"puuid"`
-	GameName string `json:"gameName"`
-	TagLine  string `json:"tagLine"`
-}
-
-// MatchInfo информация о матче
-type MatchInfo struct {
-	MatchID string `json:"matchId"`
-	Map     string `json:"map"`
-	Mode    string `json:"mode"`
-	Result  string `json:"result"`
-	Score   string `json:"score"`
-	Date    string `json:"date"`
-}
-
-// GetPlayerByName получает информацию об игроке по имени
-func (c *ValorantAPIClient) GetPlayerByName(gameName, tag string) (*PlayerInfo, error) {
-	// TODO: Реализовать запрос к API
-	// Пример URL: /riot/account/v1/accounts/by-riot-id/{gameName}/{tag}
-
-	url := fmt.Sprintf("%s/riot/account/v1/accounts/by-riot-id/%s/%s", c.BaseURL, gameName, tag)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+// getJSON получает ответ эндпоинта типа kind из кэша или API и разбирает JSON в out
+func (c *ValorantAPIClient) getJSON(ctx context.Context, kind CacheKind, url string, out interface{}) error {
+	var body []byte
+	var err error
+	if c.Cache != nil {
+		body, err = c.Cache.Do(ctx, kind, url, func(stale *cacheEntry) (*apiResponse, error) {
+			return c.fetch(ctx, url, stale)
+		})
+	} else {
+		var resp *apiResponse
+		if resp, err = c.fetch(ctx, url, nil); err == nil {
+			body = resp.Body
+		}
 	}
-
-	req.Header.Set("X-Riot-Token", c.APIKey)
-
-	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer resp.Body.Close()
+	return json.Unmarshal(body, out)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+// fetch выполняет GET-запрос с повторами; для устаревшей записи stale запрос условный.
+// Повтор не начинается, если до дедлайна ctx пауза не успеет закончиться.
+func (c *ValorantAPIClient) fetch(ctx context.Context, url string, stale *cacheEntry) (*apiResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.get(ctx, url, stale)
+		if err == nil {
+			return resp, nil
+		}
+
+		var apiErr *ValorantAPIError
+		if !errors.As(err, &apiErr) || !apiErr.retryable() || attempt >= c.MaxRetries {
+			return nil, err
+		}
+
+		delay := retryBackoff(attempt)
+		if apiErr.RetryAfter > 0 {
+			// Лимитер уже приостановлен до Retry-After; без лимитера ждем сами
+			delay = 0
+			if c.Limiter == nil {
+				delay = apiErr.RetryAfter
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, err
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// get выполняет один GET-запрос и переводит неуспешный ответ в ValorantAPIError
+func (c *ValorantAPIClient) get(ctx context.Context, url string, stale *cacheEntry) (*apiResponse, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
 	}
 
-	var player PlayerInfo
-	err = json.Unmarshal(body, &player)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return &player, nil
-}
-
-// GetPlayerMatches получает матчи игрока
-func (c *ValorantAPIClient) GetPlayerMatches(puuid string, count int) ([]string, error) {
-	// TODO: Реализовать запрос к API
-	// Пример URL: /match/v1/matchlists/by-puuid/{puuid}
-
-	url := fmt.Sprintf("%s/match/v1/matchlists/by-puuid/%s?count=%d", c.BaseURL, puuid, count)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+	if c.APIKey != "" {
+		req.Header.Set(c.AuthHeader, c.APIKey)
+	}
+	if stale != nil {
+		if stale.etag != "" {
+			req.Header.Set("If-None-Match", stale.etag)
+		}
+		if stale.lastModified != "" {
+			req.Header.Set("If-Modified-Since", stale.lastModified)
+		}
 	}
-
-	req.Header.Set("X-Riot-Token", c.APIKey)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		// Отмена вызывающим — не сбой API, ее не повторяем
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, &ValorantAPIError{Kind: ErrValorantUpstream, Err: err}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	if c.Limiter != nil {
+		c.Limiter.Update(resp.Header)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	if resp.StatusCode == http.StatusNotModified && stale != nil {
+		return &apiResponse{NotModified: true}, nil
 	}
 
-	var response struct {
-		Matches []struct {
-			MatchID string `json:"matchId"`
-		} `json:"matches"`
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &ValorantAPIError{Kind: valorantErrorKind(resp.StatusCode), StatusCode: resp.StatusCode}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			if apiErr.RetryAfter > 0 && c.Limiter != nil {
+				c.Limiter.Block(apiErr.RetryAfter)
+			}
+		}
+		return nil, apiErr
 	}
 
-	err = json.Unmarshal(body, &response)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, &ValorantAPIError{Kind: ErrValorantUpstream, Err: err}
 	}
+	return &apiResponse{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
 
-	var matchIDs []string
-	for _, match := range response.Matches {
-		matchIDs = append(matchIDs, match.MatchID)
+// sleepContext ждет duration или отмены ctx
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-
-	return matchIDs, nil
 }
 
-// GetMatchDetails получает детали матча
-func (c *ValorantAPIClient) GetMatchDetails(matchID string) (*MatchInfo, error) {
-	// TODO: Реализовать запрос к API
-	// Пример URL: /match/v1/matches/{matchId}
-
-	url := fmt.Sprintf("%s/match/v1/matches/%s", c.BaseURL, matchID)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+// valorantErrorKind тип ошибки по HTTP-статусу ответа
+func valorantErrorKind(status int) error {
+	switch status {
+	case http.StatusNotFound:
+		return ErrValorantNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrValorantUnauthorized
+	case http.StatusTooManyRequests:
+		return ErrValorantRateLimited
+	default:
+		return ErrValorantUpstream
 	}
+}
 
-	req.Header.Set("X-Riot-Token", c.APIKey)
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// retryable сообщает, имеет ли смысл повторить запрос: 429, 5xx и сетевые ошибки
+func (e *ValorantAPIError) retryable() bool {
+	return e.StatusCode == 0 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-	}
+// retryBackoff пауза перед повтором attempt: экспоненциальная с равномерным разбросом в половину паузы
+func retryBackoff(attempt int) time.Duration {
+	delay := valorantRetryBackoff << attempt
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// parseRetryAfter разбирает Retry-After в секундах; 0 — заголовка нет или он некорректен
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
 	}
+	return time.Duration(seconds) * time.Second
+}
 
-	var match MatchInfo
-	err = json.Unmarshal(body, &match)
-	if err != nil {
-		return nil, err
+// ValorantClient общий поставщик данных Valorant, инициализируется в InitValorantClient
+var ValorantClient ValorantProvider
+
+// InitValorantClient создает общий поставщик из конфигурации.
+// VALORANT_API_URL заменяет адрес API, например для запуска против собственного фейкового сервера.
+func InitValorantClient(cfg *config.Config) {
+	// Один лимитер на все запросы: лимиты Riot действуют на ключ приложения целиком
+	limiter := NewRateLimiter(cfg.ValorantRateLimit)
+	ValorantCache = NewResponseCache(cfg.ValorantCacheSize, DefaultCacheTTL, true)
+
+	switch cfg.ValorantProvider {
+	case ProviderHenrik:
+		ValorantClient = NewHenrikProvider(cfg.ValorantAPIKey, cfg.ValorantAPIURL, limiter, ValorantCache)
+	case ProviderFake:
+		baseURL, err := valorantfake.Start(cfg.ValorantFakeAddr)
+		if err != nil {
+			log.Fatal("Failed to start fake Valorant API:", err)
+		}
+		log.Println("Using fake Valorant API at", baseURL)
+		ValorantClient = NewRiotProvider("", baseURL, limiter, ValorantCache)
+	default:
+		ValorantClient = NewRiotProvider(cfg.ValorantAPIKey, cfg.ValorantAPIURL, limiter, ValorantCache)
 	}
-
-	return &match, nil
 }
 
-// GetPlayerRank получает ранг игрока
-func (c *ValorantAPIClient) GetPlayerRank(puuid string) (string, int, error) {
-	// TODO: Реализовать запрос к API
-	// Пример URL: /mmr/v1/players/{puuid}
+// PlayerInfo информация об игроке
+type PlayerInfo struct {
+	Puuid        string `json:"puuid"`
+	GameName     string `json:"gameName"`
+	TagLine      string `json:"tagLine"`
+	AccountLevel int    `json:"accountLevel"` // 0, если поставщик не сообщает уровень
+}
 
-	url := fmt.Sprintf("%s/mmr/v1/players/%s", c.BaseURL, puuid)
+// MMRInfo текущий ранг игрока
+type MMRInfo struct {
+	Tier       string `json:"tier"` // Название ранга из models.RankTiers
+	RankRating int    `json:"rankRating"`
+}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", 0, err
-	}
+// MatchInfo информация о матче
+type MatchInfo struct {
+	MatchID  string            `json:"matchId"`
+	Map      string            `json:"map"`
+	Mode     string            `json:"mode"`
+	Score    string            `json:"score"`
+	Date     string            `json:"date"`
+	Duration int               `json:"duration"`
+	Players  []MatchPlayerInfo `json:"players"`
+}
 
-	req.Header.Set("X-Riot-Token", c.APIKey)
+// MatchPlayerInfo статистика игрока в матче
+type MatchPlayerInfo struct {
+	Puuid       string `json:"puuid"`
+	Agent       string `json:"agent"`
+	Won         bool   `json:"won"`
+	Kills       int    `json:"kills"`
+	Deaths      int    `json:"deaths"`
+	Assists     int    `json:"assists"`
+	Score       int    `json:"score"`
+	Damage      int    `json:"damage"`
+	Headshots   int    `json:"headshots"` // Попадания в голову, а не убийства в голову
+	Bodyshots   int    `json:"bodyshots"`
+	Legshots    int    `json:"legshots"`
+	FirstKills  int    `json:"firstKills"`
+	FirstDeaths int    `json:"firstDeaths"`
+}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return "", 0, err
-	}
-	defer resp.Body.Close()
+// roundKill убийство в раунде для подсчета первых убийств
+type roundKill struct {
+	Time   int
+	Killer string
+	Victim string
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", 0, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+// applyFirstKills считает первые убийства и смерти по раундам и записывает их игрокам матча
+func applyFirstKills(match *MatchInfo, rounds [][]roundKill) {
+	firstKills := make(map[string]int)
+	firstDeaths := make(map[string]int)
+	for _, kills := range rounds {
+		if len(kills) == 0 {
+			continue
+		}
+		first := kills[0]
+		for _, kill := range kills[1:] {
+			if kill.Time < first.Time {
+				first = kill
+			}
+		}
+		firstKills[first.Killer]++
+		firstDeaths[first.Victim]++
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", 0, err
+	for i := range match.Players {
+		match.Players[i].FirstKills = firstKills[match.Players[i].Puuid]
+		match.Players[i].FirstDeaths = firstDeaths[match.Players[i].Puuid]
 	}
+}
 
-	var response struct {
-		CurrentTier string `json:"currentTier"`
-		RankRating  int    `json:"rankRating"`
+// roundScore счет матча в виде "выигранные-проигранные раунды" победившей команды
+func roundScore(won, lost int) string {
+	if lost > won {
+		won, lost = lost, won
 	}
+	return fmt.Sprintf("%d-%d", won, lost)
+}
 
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return "", 0, err
+// matchDate дата начала матча в формате RFC3339
+func matchDate(start time.Time) string {
+	if start.Unix() <= 0 {
+		return ""
 	}
+	return start.UTC().Format(time.RFC3339)
+}
 
-	return response.CurrentTier, response.RankRating, nil
+// normalizeMode приводит режим игры к виду "competitive", "unrated" и т.д.
+func normalizeMode(mode string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(mode)), " ", "")
 }
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+	"valorant-app/database"
+	"valorant-app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CacheKind тип эндпоинта Valorant API; от него зависит срок жизни ответа в кэше
+type CacheKind string
+
+const (
+	CacheAccount   CacheKind = "account"
+	CacheMMR       CacheKind = "mmr"
+	CacheMatchList CacheKind = "matchlist"
+	CacheMatch     CacheKind = "match"
+	CacheContent   CacheKind = "content"
+)
+
+// DefaultValorantCacheSize количество ответов в памяти по умолчанию
+const DefaultValorantCacheSize = 1000
+
+// DefaultCacheTTL сроки жизни ответов по типам эндпоинтов; 0 — ответ не устаревает.
+// Детали матча не меняются, поэтому хранятся бессрочно и дополнительно сохраняются в БД.
+var DefaultCacheTTL = map[CacheKind]time.Duration{
+	CacheAccount:   24 * time.Hour,
+	CacheMMR:       10 * time.Minute,
+	CacheMatchList: 2 * time.Minute,
+	CacheMatch:     0,
+	CacheContent:   24 * time.Hour,
+}
+
+// ValorantCache общий кэш ответов Valorant API, создается в InitValorantClient
+var ValorantCache *ResponseCache
+
+// apiResponse успешный ответ API; NotModified — ответ 304 на условный запрос
+type apiResponse struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// cacheEntry сохраненный ответ; нулевой expiresAt — ответ не устаревает
+type cacheEntry struct {
+	key          string
+	body         []byte
+	etag         string
+	lastModified string
+	expiresAt    time.Time
+}
+
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return e.expiresAt.IsZero() || now.Before(e.expiresAt)
+}
+
+// cacheCall запрос к API, который уже выполняется для ключа; остальные вызывающие ждут его результат
+type cacheCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// CacheStats счетчики кэша по типу эндпоинта
+type CacheStats struct {
+	Hits        int64 `json:"hits"`        // Ответ из памяти или из уже выполняющегося запроса
+	StoreHits   int64 `json:"store_hits"`  // Ответ из БД (только детали матчей)
+	Misses      int64 `json:"misses"`      // Запрос к API
+	Revalidated int64 `json:"revalidated"` // API ответил 304 на условный запрос
+	Stale       int64 `json:"stale"`       // Отдан устаревший ответ, потому что API недоступен
+}
+
+// CacheSnapshot состояние кэша для мониторинга
+type CacheSnapshot struct {
+	Entries  int                       `json:"entries"`
+	Capacity int                       `json:"capacity"`
+	Kinds    map[CacheKind]*CacheStats `json:"kinds"`
+}
+
+// ResponseCache LRU-кэш ответов Valorant API в памяти с отдельным сроком жизни для каждого типа эндпоинта.
+// Детали матчей дополнительно хранятся в таблице valorant_match_caches. Устаревший ответ с ETag или
+// Last-Modified обновляется условным запросом, а при недоступности API отдается как есть.
+// Одновременные запросы одного ключа объединяются в один запрос к API.
+type ResponseCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      map[CacheKind]time.Duration
+	order    *list.List // От недавно использованных к давно
+	entries  map[string]*list.Element
+	inflight map[string]*cacheCall
+	stats    map[CacheKind]*CacheStats
+	persist  bool
+}
+
+// NewResponseCache создает кэш на capacity ответов; persist включает хранение деталей матчей в БД
+func NewResponseCache(capacity int, ttl map[CacheKind]time.Duration, persist bool) *ResponseCache {
+	if capacity <= 0 {
+		capacity = DefaultValorantCacheSize
+	}
+	return &ResponseCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*cacheCall),
+		stats:    make(map[CacheKind]*CacheStats),
+		persist:  persist,
+	}
+}
+
+// Do возвращает ответ для key из кэша, а если его нет или он устарел — через fetch.
+// fetch получает устаревшую запись (или nil), чтобы сделать условный запрос.
+func (c *ResponseCache) Do(ctx context.Context, kind CacheKind, key string, fetch func(stale *cacheEntry) (*apiResponse, error)) ([]byte, error) {
+	for {
+		c.mu.Lock()
+		entry := c.lookup(key)
+		if entry != nil && entry.fresh(time.Now()) && !c.refreshRequested(ctx, kind) {
+			c.kindStats(kind).Hits++
+			c.mu.Unlock()
+			return entry.body, nil
+		}
+
+		if call, ok := c.inflight[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			// Запрос прервал отменой другой вызывающий — пробуем сами
+			if call.err != nil && ctx.Err() == nil && (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) {
+				continue
+			}
+			if call.err == nil {
+				c.mu.Lock()
+				c.kindStats(kind).Hits++
+				c.mu.Unlock()
+			}
+			return call.body, call.err
+		}
+
+		call := &cacheCall{done: make(chan struct{})}
+		c.inflight[key] = call
+		c.mu.Unlock()
+
+		call.body, call.err = c.load(kind, key, entry, fetch)
+
+		c.mu.Lock()
+		delete(c.inflight, key)
+		c.mu.Unlock()
+		close(call.done)
+		return call.body, call.err
+	}
+}
+
+// cacheRefreshKey ключ контекста для WithCacheRefresh
+type cacheRefreshKey struct{}
+
+// WithCacheRefresh помечает ctx так, что ответы с ограниченным сроком жизни (аккаунт, ранг, история матчей)
+// запрашиваются заново — условным запросом, если известен ETag. Бессрочные детали матчей берутся из кэша.
+func WithCacheRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheRefreshKey{}, true)
+}
+
+// refreshRequested сообщает, нужно ли обновить свежий ответ типа kind по WithCacheRefresh
+func (c *ResponseCache) refreshRequested(ctx context.Context, kind CacheKind) bool {
+	return ctx.Value(cacheRefreshKey{}) != nil && c.ttl[kind] > 0
+}
+
+// load получает ответ из БД или API и сохраняет его в кэш
+func (c *ResponseCache) load(kind CacheKind, key string, stale *cacheEntry, fetch func(stale *cacheEntry) (*apiResponse, error)) ([]byte, error) {
+	if stale == nil && c.persistent(kind) {
+		stored, err := loadStoredResponse(key)
+		if err != nil {
+			log.Printf("Failed to load cached Valorant response %s: %v", key, err)
+		}
+		if stored != nil {
+			c.mu.Lock()
+			c.kindStats(kind).StoreHits++
+			c.add(&cacheEntry{key: key, body: []byte(stored.Body), etag: stored.ETag, lastModified: stored.LastModified})
+			c.mu.Unlock()
+			return []byte(stored.Body), nil
+		}
+	}
+
+	c.mu.Lock()
+	c.kindStats(kind).Misses++
+	c.mu.Unlock()
+
+	resp, err := fetch(stale)
+	if err != nil {
+		if stale != nil && (errors.Is(err, ErrValorantRateLimited) || errors.Is(err, ErrValorantUpstream)) {
+			log.Printf("Serving stale Valorant response %s: %v", key, err)
+			c.mu.Lock()
+			c.kindStats(kind).Stale++
+			c.mu.Unlock()
+			return stale.body, nil
+		}
+		return nil, err
+	}
+
+	entry := &cacheEntry{key: key, body: resp.Body, etag: resp.ETag, lastModified: resp.LastModified}
+	if resp.NotModified && stale != nil {
+		entry.body, entry.etag, entry.lastModified = stale.body, stale.etag, stale.lastModified
+	}
+	if ttl := c.ttl[kind]; ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	if resp.NotModified {
+		c.kindStats(kind).Revalidated++
+	}
+	c.add(entry)
+	c.mu.Unlock()
+
+	if c.persistent(kind) && !resp.NotModified {
+		if err := saveStoredResponse(entry); err != nil {
+			log.Printf("Failed to store Valorant response %s: %v", key, err)
+		}
+	}
+	return entry.body, nil
+}
+
+// persistent сообщает, хранятся ли ответы этого типа в БД
+func (c *ResponseCache) persistent(kind CacheKind) bool {
+	return c.persist && kind == CacheMatch
+}
+
+// lookup находит запись и помечает ее недавно использованной; вызывается под mu
+func (c *ResponseCache) lookup(key string) *cacheEntry {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry)
+}
+
+// add добавляет или заменяет запись и вытесняет давно не использованные; вызывается под mu
+func (c *ResponseCache) add(entry *cacheEntry) {
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// kindStats счетчики типа kind; вызывается под mu
+func (c *ResponseCache) kindStats(kind CacheKind) *CacheStats {
+	stats, ok := c.stats[kind]
+	if !ok {
+		stats = &CacheStats{}
+		c.stats[kind] = stats
+	}
+	return stats
+}
+
+// Stats возвращает копию счетчиков по всем типам эндпоинтов
+func (c *ResponseCache) Stats() CacheSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := CacheSnapshot{
+		Entries:  c.order.Len(),
+		Capacity: c.capacity,
+		Kinds:    make(map[CacheKind]*CacheStats, len(c.ttl)),
+	}
+	for kind := range c.ttl {
+		stats := *c.kindStats(kind)
+		snapshot.Kinds[kind] = &stats
+	}
+	return snapshot
+}
+
+// loadStoredResponse находит сохраненный ответ в БД; nil — ответа нет
+func loadStoredResponse(key string) (*models.ValorantMatchCache, error) {
+	var stored models.ValorantMatchCache
+	err := database.DB.Where("key = ?", key).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// saveStoredResponse сохраняет ответ в БД, заменяя прежний
+func saveStoredResponse(entry *cacheEntry) error {
+	stored := models.ValorantMatchCache{
+		Key:          entry.key,
+		Body:         string(entry.body),
+		ETag:         entry.etag,
+		LastModified: entry.lastModified,
+	}
+	return database.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&stored).Error
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testCacheTTL короткие сроки жизни: история матчей устаревает, детали матча — нет
+var testCacheTTL = map[CacheKind]time.Duration{
+	CacheMatchList: time.Minute,
+	CacheMatch:     0,
+}
+
+// countingFetch отдает body и считает обращения к API
+func countingFetch(calls *atomic.Int32, body string) func(stale *cacheEntry) (*apiResponse, error) {
+	return func(stale *cacheEntry) (*apiResponse, error) {
+		calls.Add(1)
+		return &apiResponse{Body: []byte(body), ETag: `"v1"`}, nil
+	}
+}
+
+// expireEntry переводит запись в устаревшие, как будто ее срок жизни прошел
+func expireEntry(t *testing.T, cache *ResponseCache, key string) {
+	t.Helper()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	element, ok := cache.entries[key]
+	if !ok {
+		t.Fatalf("entry %s is not cached", key)
+	}
+	element.Value.(*cacheEntry).expiresAt = time.Now().Add(-time.Second)
+}
+
+func cacheGet(t *testing.T, cache *ResponseCache, kind CacheKind, key string, fetch func(stale *cacheEntry) (*apiResponse, error)) string {
+	t.Helper()
+
+	body, err := cache.Do(context.Background(), kind, key, fetch)
+	if err != nil {
+		t.Fatalf("Do(%s): %v", key, err)
+	}
+	return string(body)
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	cache := NewResponseCache(0, testCacheTTL, false)
+	var calls atomic.Int32
+
+	cacheGet(t, cache, CacheMatchList, "list", countingFetch(&calls, "first"))
+	if body := cacheGet(t, cache, CacheMatchList, "list", countingFetch(&calls, "second")); body != "first" {
+		t.Fatalf("fresh entry body = %q, want first", body)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("fetches = %d, want 1", calls.Load())
+	}
+
+	expireEntry(t, cache, "list")
+	var stale *cacheEntry
+	body := cacheGet(t, cache, CacheMatchList, "list", func(entry *cacheEntry) (*apiResponse, error) {
+		stale = entry
+		return countingFetch(&calls, "second")(entry)
+	})
+	if body != "second" || calls.Load() != 2 {
+		t.Fatalf("expired entry body = %q after %d fetches, want second after 2", body, calls.Load())
+	}
+	if stale == nil || stale.etag != `"v1"` {
+		t.Errorf("fetch got stale entry %+v, want the expired one for a conditional request", stale)
+	}
+
+	// Ответ 304 продлевает прежний ответ
+	expireEntry(t, cache, "list")
+	body = cacheGet(t, cache, CacheMatchList, "list", func(*cacheEntry) (*apiResponse, error) {
+		return &apiResponse{NotModified: true}, nil
+	})
+	if body != "second" {
+		t.Errorf("revalidated body = %q, want second", body)
+	}
+
+	// Бессрочные ответы не запрашиваются повторно даже с WithCacheRefresh
+	cacheGet(t, cache, CacheMatch, "match", countingFetch(&calls, "match"))
+	if _, err := cache.Do(WithCacheRefresh(context.Background()), CacheMatch, "match", countingFetch(&calls, "again")); err != nil {
+		t.Fatalf("Do with refresh: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("fetches = %d, want 3", calls.Load())
+	}
+
+	stats := cache.Stats().Kinds[CacheMatchList]
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Revalidated != 1 {
+		t.Errorf("matchlist stats = %+v, want 1 hit, 3 misses, 1 revalidated", *stats)
+	}
+}
+
+func TestResponseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewResponseCache(2, testCacheTTL, false)
+	var calls atomic.Int32
+
+	cacheGet(t, cache, CacheMatch, "a", countingFetch(&calls, "a"))
+	cacheGet(t, cache, CacheMatch, "b", countingFetch(&calls, "b"))
+	// Обращение к a делает давно не использованной b
+	cacheGet(t, cache, CacheMatch, "a", countingFetch(&calls, "a"))
+	cacheGet(t, cache, CacheMatch, "c", countingFetch(&calls, "c"))
+
+	if entries := cache.Stats().Entries; entries != 2 {
+		t.Fatalf("entries = %d, want 2", entries)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("fetches = %d, want 3", calls.Load())
+	}
+
+	cacheGet(t, cache, CacheMatch, "a", countingFetch(&calls, "a"))
+	if calls.Load() != 3 {
+		t.Errorf("a was evicted, want it kept as recently used")
+	}
+	cacheGet(t, cache, CacheMatch, "b", countingFetch(&calls, "b"))
+	if calls.Load() != 4 {
+		t.Errorf("b was kept, want it evicted as least recently used")
+	}
+}
+
+func TestResponseCacheDeduplicatesInflight(t *testing.T) {
+	cache := NewResponseCache(0, testCacheTTL, false)
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	fetch := func(*cacheEntry) (*apiResponse, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return &apiResponse{Body: []byte("match")}, nil
+	}
+
+	const callers = 5
+	bodies := make([]string, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	run := func(i int) {
+		defer wg.Done()
+		body, err := cache.Do(context.Background(), CacheMatch, "match", fetch)
+		bodies[i], errs[i] = string(body), err
+	}
+
+	wg.Add(callers)
+	go run(0)
+	<-started
+	for i := 1; i < callers; i++ {
+		go run(i)
+	}
+	// Даем остальным вызывающим дождаться запроса в полете
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("fetches = %d, want 1", calls.Load())
+	}
+	for i := range bodies {
+		if errs[i] != nil || bodies[i] != "match" {
+			t.Errorf("caller %d got %q, %v", i, bodies[i], errs[i])
+		}
+	}
+	if stats := cache.Stats().Kinds[CacheMatch]; stats.Misses != 1 || stats.Hits != callers-1 {
+		t.Errorf("stats = %+v, want 1 miss and %d hits", *stats, callers-1)
+	}
+}
+
+func TestResponseCacheInflightErrorIsShared(t *testing.T) {
+	cache := NewResponseCache(0, testCacheTTL, false)
+	var calls atomic.Int32
+
+	fetch := func(*cacheEntry) (*apiResponse, error) {
+		calls.Add(1)
+		return nil, &ValorantAPIError{Kind: ErrValorantNotFound, StatusCode: http.StatusNotFound}
+	}
+	if _, err := cache.Do(context.Background(), CacheMatch, "missing", fetch); !errors.Is(err, ErrValorantNotFound) {
+		t.Fatalf("Do = %v, want ErrValorantNotFound", err)
+	}
+	// Ошибки не кэшируются
+	if _, err := cache.Do(context.Background(), CacheMatch, "missing", fetch); !errors.Is(err, ErrValorantNotFound) {
+		t.Fatalf("second Do = %v, want ErrValorantNotFound", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("fetches = %d, want 2", calls.Load())
+	}
+}
+
+func TestResponseCacheServesStaleOnUpstreamError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantStale bool
+	}{
+		{"upstream unavailable", &ValorantAPIError{Kind: ErrValorantUpstream, StatusCode: http.StatusBadGateway}, true},
+		{"rate limited", &ValorantAPIError{Kind: ErrValorantRateLimited, StatusCode: http.StatusTooManyRequests}, true},
+		{"not found", &ValorantAPIError{Kind: ErrValorantNotFound, StatusCode: http.StatusNotFound}, false},
+		{"cancelled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewResponseCache(0, testCacheTTL, false)
+			var calls atomic.Int32
+			cacheGet(t, cache, CacheMatchList, "list", countingFetch(&calls, "cached"))
+			expireEntry(t, cache, "list")
+
+			body, err := cache.Do(context.Background(), CacheMatchList, "list", func(*cacheEntry) (*apiResponse, error) {
+				return nil, tt.err
+			})
+			stats := cache.Stats().Kinds[CacheMatchList]
+			if tt.wantStale {
+				if err != nil || string(body) != "cached" {
+					t.Fatalf("Do = %q, %v; want stale body", body, err)
+				}
+				if stats.Stale != 1 {
+					t.Errorf("stale = %d, want 1", stats.Stale)
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Do = %q, %v; want %v", body, err, tt.err)
+			}
+			if stats.Stale != 0 {
+				t.Errorf("stale = %d, want 0", stats.Stale)
+			}
+		})
+	}
+
+	// Без сохраненного ответа ошибка API возвращается вызывающему
+	cache := NewResponseCache(0, testCacheTTL, false)
+	_, err := cache.Do(context.Background(), CacheMatchList, "list", func(*cacheEntry) (*apiResponse, error) {
+		return nil, &ValorantAPIError{Kind: ErrValorantUpstream, StatusCode: http.StatusBadGateway}
+	})
+	if !errors.Is(err, ErrValorantUpstream) {
+		t.Errorf("Do without cached entry = %v, want ErrValorantUpstream", err)
+	}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// henrikBaseURL адрес community API HenrikDev
+const henrikBaseURL = "https://api.henrikdev.xyz"
+
+// HenrikProvider поставщик данных через community API в стиле HenrikDev.
+// В отличие от Riot API, отдает ранг с рейтингом и уровень аккаунта, а матчи — уже с именами агентов и карт.
+type HenrikProvider struct {
+	Client  *ValorantAPIClient
+	BaseURL string
+}
+
+// NewHenrikProvider создает поставщика HenrikDev API
+func NewHenrikProvider(apiKey, baseURL string, limiter *RateLimiter, cache *ResponseCache) *HenrikProvider {
+	if baseURL == "" {
+		baseURL = henrikBaseURL
+	}
+	return &HenrikProvider{
+		Client:  NewValorantAPIClient(apiKey, "Authorization", limiter, cache),
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+type henrikAccount struct {
+	Puuid        string `json:"puuid"`
+	Name         string `json:"name"`
+	Tag          string `json:"tag"`
+	AccountLevel int    `json:"account_level"`
+}
+
+type henrikMMR struct {
+	CurrentData struct {
+		CurrentTierPatched string `json:"currenttierpatched"`
+		RankingInTier      int    `json:"ranking_in_tier"`
+	} `json:"current_data"`
+}
+
+type henrikTeam struct {
+	HasWon    bool `json:"has_won"`
+	RoundsWon int  `json:"rounds_won"`
+}
+
+type henrikMatch struct {
+	Metadata struct {
+		MatchID    string `json:"matchid"`
+		Map        string `json:"map"`
+		ModeID     string `json:"mode_id"`
+		GameLength int    `json:"game_length"` // Секунды
+		GameStart  int64  `json:"game_start"`  // Unix-время в секундах
+	} `json:"metadata"`
+	Players struct {
+		AllPlayers []struct {
+			Puuid     string `json:"puuid"`
+			Team      string `json:"team"`
+			Character string `json:"character"`
+			Stats     struct {
+				Score     int `json:"score"`
+				Kills     int `json:"kills"`
+				Deaths    int `json:"deaths"`
+				Assists   int `json:"assists"`
+				Headshots int `json:"headshots"`
+				Bodyshots int `json:"bodyshots"`
+				Legshots  int `json:"legshots"`
+			} `json:"stats"`
+			DamageMade int `json:"damage_made"`
+		} `json:"all_players"`
+	} `json:"players"`
+	Teams struct {
+		Red  henrikTeam `json:"red"`
+		Blue henrikTeam `json:"blue"`
+	} `json:"teams"`
+	Rounds []struct {
+		PlayerStats []struct {
+			KillEvents []struct {
+				KillTimeInRound int    `json:"kill_time_in_round"`
+				KillerPuuid     string `json:"killer_puuid"`
+				VictimPuuid     string `json:"victim_puuid"`
+			} `json:"kill_events"`
+		} `json:"player_stats"`
+	} `json:"rounds"`
+}
+
+// get запрашивает эндпоинт и разбирает поле data ответа
+func (p *HenrikProvider) get(ctx context.Context, kind CacheKind, requestPath string, data interface{}) error {
+	response := struct {
+		Data interface{} `json:"data"`
+	}{Data: data}
+	return p.Client.getJSON(ctx, kind, p.BaseURL+requestPath, &response)
+}
+
+// GetAccount находит аккаунт по Riot ID
+func (p *HenrikProvider) GetAccount(ctx context.Context, region, gameName, tag string) (*PlayerInfo, error) {
+	var account henrikAccount
+	if err := p.get(ctx, CacheAccount, fmt.Sprintf("/valorant/v1/account/%s/%s", url.PathEscape(gameName), url.PathEscape(tag)), &account); err != nil {
+		return nil, err
+	}
+	return &PlayerInfo{
+		Puuid:        account.Puuid,
+		GameName:     account.Name,
+		TagLine:      account.Tag,
+		AccountLevel: account.AccountLevel,
+	}, nil
+}
+
+// GetMMR возвращает текущий ранг и рейтинг внутри ранга
+func (p *HenrikProvider) GetMMR(ctx context.Context, region, puuid string) (*MMRInfo, error) {
+	var mmr henrikMMR
+	if err := p.get(ctx, CacheMMR, fmt.Sprintf("/valorant/v2/by-puuid/mmr/%s/%s", url.PathEscape(region), url.PathEscape(puuid)), &mmr); err != nil {
+		return nil, err
+	}
+	return &MMRInfo{Tier: mmr.CurrentData.CurrentTierPatched, RankRating: mmr.CurrentData.RankingInTier}, nil
+}
+
+// GetMatchList возвращает ID последних count матчей
+func (p *HenrikProvider) GetMatchList(ctx context.Context, region, puuid string, count int) ([]string, error) {
+	var matches []henrikMatch
+	requestPath := fmt.Sprintf("/valorant/v3/by-puuid/matches/%s/%s?size=%d", url.PathEscape(region), url.PathEscape(puuid), count)
+	if err := p.get(ctx, CacheMatchList, requestPath, &matches); err != nil {
+		return nil, err
+	}
+
+	matchIDs := make([]string, 0, len(matches))
+	for _, match := range matches {
+		matchIDs = append(matchIDs, match.Metadata.MatchID)
+	}
+	return matchIDs, nil
+}
+
+// GetMatchDetails возвращает детали матча
+func (p *HenrikProvider) GetMatchDetails(ctx context.Context, region, matchID string) (*MatchInfo, error) {
+	var match henrikMatch
+	if err := p.get(ctx, CacheMatch, "/valorant/v2/match/"+url.PathEscape(matchID), &match); err != nil {
+		return nil, err
+	}
+
+	info := &MatchInfo{
+		MatchID:  match.Metadata.MatchID,
+		Map:      match.Metadata.Map,
+		Mode:     normalizeMode(match.Metadata.ModeID),
+		Score:    roundScore(match.Teams.Red.RoundsWon, match.Teams.Blue.RoundsWon),
+		Date:     matchDate(time.Unix(match.Metadata.GameStart, 0)),
+		Duration: match.Metadata.GameLength,
+	}
+
+	won := map[string]bool{"red": match.Teams.Red.HasWon, "blue": match.Teams.Blue.HasWon}
+	for _, player := range match.Players.AllPlayers {
+		info.Players = append(info.Players, MatchPlayerInfo{
+			Puuid:     player.Puuid,
+			Agent:     player.Character,
+			Won:       won[strings.ToLower(player.Team)],
+			Kills:     player.Stats.Kills,
+			Deaths:    player.Stats.Deaths,
+			Assists:   player.Stats.Assists,
+			Score:     player.Stats.Score,
+			Damage:    player.DamageMade,
+			Headshots: player.Stats.Headshots,
+			Bodyshots: player.Stats.Bodyshots,
+			Legshots:  player.Stats.Legshots,
+		})
+	}
+
+	kills := make([][]roundKill, 0, len(match.Rounds))
+	for _, round := range match.Rounds {
+		var roundKills []roundKill
+		for _, stats := range round.PlayerStats {
+			for _, kill := range stats.KillEvents {
+				roundKills = append(roundKills, roundKill{Time: kill.KillTimeInRound, Killer: kill.KillerPuuid, Victim: kill.VictimPuuid})
+			}
+		}
+		kills = append(kills, roundKills)
+	}
+	applyFirstKills(info, kills)
+
+	return info, nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultValorantRateLimit лимиты ключа разработчика Riot: 20 запросов в секунду и 100 за две минуты
+const DefaultValorantRateLimit = "20:1,100:120"
+
+// RateLimiter общий token-bucket лимитер запросов к Valorant API с несколькими окнами.
+// Лимиты уточняются по заголовкам ответов (X-App-Rate-Limit у Riot, X-RateLimit-* у community API),
+// ответ 429 с Retry-After приостанавливает все запросы.
+type RateLimiter struct {
+	mu           sync.Mutex
+	buckets      []*tokenBucket
+	blockedUntil time.Time
+}
+
+// tokenBucket окно лимита: capacity запросов за window
+type tokenBucket struct {
+	capacity float64
+	window   time.Duration
+	tokens   float64
+	updated  time.Time
+}
+
+// NewRateLimiter создает лимитер из описания лимитов в формате Riot: "20:1,100:120" (запросы:секунды).
+// Пустая строка — DefaultValorantRateLimit.
+func NewRateLimiter(limits string) *RateLimiter {
+	if limits == "" {
+		limits = DefaultValorantRateLimit
+	}
+	limiter := &RateLimiter{}
+	limiter.buckets = parseRateLimits(limits, time.Now())
+	return limiter
+}
+
+// parseRateLimits разбирает лимиты "запросы:секунды" через запятую; некорректные пары пропускаются
+func parseRateLimits(value string, now time.Time) []*tokenBucket {
+	var buckets []*tokenBucket
+	for _, pair := range strings.Split(value, ",") {
+		count, seconds, ok := parseRatePair(pair)
+		if !ok || count <= 0 || seconds <= 0 {
+			continue
+		}
+		buckets = append(buckets, &tokenBucket{
+			capacity: float64(count),
+			window:   time.Duration(seconds) * time.Second,
+			tokens:   float64(count),
+			updated:  now,
+		})
+	}
+	return buckets
+}
+
+func parseRatePair(pair string) (int, int, bool) {
+	parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	first, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	second, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return first, second, true
+}
+
+// refill пополняет окно пропорционально прошедшему времени
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens += elapsed.Seconds() / b.window.Seconds() * b.capacity
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.updated = now
+}
+
+// wait время до появления целого токена в окне
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.capacity * float64(b.window))
+}
+
+// Wait блокирует вызывающего, пока все окна не позволят сделать запрос, и занимает токен в каждом.
+// При отмене ctx возвращает ctx.Err(), не занимая токенов.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		delay := l.reserve(time.Now())
+		if delay == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve занимает токены, если запрос можно сделать сейчас, иначе возвращает время ожидания
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+
+	var delay time.Duration
+	for _, bucket := range l.buckets {
+		bucket.refill(now)
+		if wait := bucket.wait(); wait > delay {
+			delay = wait
+		}
+	}
+	if delay > 0 {
+		return delay
+	}
+
+	for _, bucket := range l.buckets {
+		bucket.tokens--
+	}
+	return 0
+}
+
+// Block приостанавливает все запросы на duration, например по Retry-After
+func (l *RateLimiter) Block(duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(duration); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// Update уточняет лимиты по заголовкам ответа
+func (l *RateLimiter) Update(header http.Header) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Riot: X-App-Rate-Limit "20:1,100:120" и X-App-Rate-Limit-Count "3:1,40:120" (сделано:секунды)
+	if limits := header.Get("X-App-Rate-Limit"); limits != "" {
+		l.applyLimits(limits, now)
+	}
+	if counts := header.Get("X-App-Rate-Limit-Count"); counts != "" {
+		for _, pair := range strings.Split(counts, ",") {
+			count, seconds, ok := parseRatePair(pair)
+			if !ok {
+				continue
+			}
+			for _, bucket := range l.buckets {
+				if bucket.window == time.Duration(seconds)*time.Second {
+					bucket.refill(now)
+					if remaining := bucket.capacity - float64(count); remaining < bucket.tokens {
+						bucket.tokens = remaining
+					}
+				}
+			}
+		}
+	}
+
+	// Community API: X-RateLimit-Remaining и X-RateLimit-Reset (секунды до сброса)
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil || remaining > 0 {
+		return
+	}
+	if reset, err := strconv.Atoi(header.Get("X-RateLimit-Reset")); err == nil && reset > 0 {
+		if until := now.Add(time.Duration(reset) * time.Second); until.After(l.blockedUntil) {
+			l.blockedUntil = until
+		}
+	}
+}
+
+// applyLimits заменяет окна, если сервер сообщил другие лимиты; совпадающие окна сохраняют остаток токенов
+func (l *RateLimiter) applyLimits(limits string, now time.Time) {
+	buckets := parseRateLimits(limits, now)
+	if len(buckets) == 0 {
+		return
+	}
+	for _, bucket := range buckets {
+		for _, current := range l.buckets {
+			if current.window == bucket.window {
+				current.refill(now)
+				if current.tokens < bucket.tokens {
+					bucket.tokens = current.tokens
+				}
+			}
+		}
+	}
+	l.buckets = buckets
+}
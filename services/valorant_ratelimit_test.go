@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserveWaitsForToken(t *testing.T) {
+	now := time.Now()
+	limiter := &RateLimiter{buckets: parseRateLimits("2:1,3:10", now)}
+
+	for i := 0; i < 2; i++ {
+		if delay := limiter.reserve(now); delay != 0 {
+			t.Fatalf("request %d delayed by %v", i+1, delay)
+		}
+	}
+
+	// Секундное окно пусто: токен появится через 1/2 секунды
+	if delay := limiter.reserve(now); delay != 500*time.Millisecond {
+		t.Fatalf("delay = %v, want 500ms", delay)
+	}
+	if delay := limiter.reserve(now.Add(500 * time.Millisecond)); delay != 0 {
+		t.Fatalf("delay after refill = %v, want 0", delay)
+	}
+
+	// Теперь пусто десятисекундное окно: ждем его, хотя секундное уже пополнилось
+	later := now.Add(time.Second)
+	delay := limiter.reserve(later)
+	if want := 10*time.Second/3 - time.Second; delay < want-time.Millisecond || delay > want+time.Millisecond {
+		t.Fatalf("delay = %v, want about %v", delay, want)
+	}
+	if delay := limiter.reserve(later.Add(delay)); delay != 0 {
+		t.Fatalf("delay after waiting = %v, want 0", delay)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter("1:60")
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Wait = %v, want context.DeadlineExceeded", err)
+	}
+	if tokens := limiter.buckets[0].tokens; tokens >= 1 || tokens < 0 {
+		t.Errorf("tokens after cancelled Wait = %v", tokens)
+	}
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  string
+		header  http.Header
+		buckets int
+		// минимальная и максимальная ожидаемая пауза перед следующим запросом
+		minDelay, maxDelay time.Duration
+	}{
+		{
+			name:    "no rate limit headers",
+			limits:  "20:1,100:120",
+			header:  http.Header{},
+			buckets: 2,
+		},
+		{
+			name:     "riot limits replace buckets",
+			limits:   "20:1,100:120",
+			header:   http.Header{"X-App-Rate-Limit": {"1:1"}, "X-App-Rate-Limit-Count": {"1:1"}},
+			buckets:  1,
+			minDelay: 900 * time.Millisecond,
+			maxDelay: time.Second,
+		},
+		{
+			name:     "riot count drains matching window",
+			limits:   "20:1,100:120",
+			header:   http.Header{"X-App-Rate-Limit-Count": {"3:1,100:120"}},
+			buckets:  2,
+			minDelay: 1100 * time.Millisecond,
+			maxDelay: 1200 * time.Millisecond,
+		},
+		{
+			name:    "riot count below used tokens is ignored",
+			limits:  "20:1,100:120",
+			header:  http.Header{"X-App-Rate-Limit-Count": {"0:1,0:120"}},
+			buckets: 2,
+		},
+		{
+			name:     "community remaining zero blocks until reset",
+			limits:   "20:1",
+			header:   http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"30"}},
+			buckets:  1,
+			minDelay: 29 * time.Second,
+			maxDelay: 30 * time.Second,
+		},
+		{
+			name:    "community remaining requests do not block",
+			limits:  "20:1",
+			header:  http.Header{"X-Ratelimit-Remaining": {"5"}, "X-Ratelimit-Reset": {"30"}},
+			buckets: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.limits)
+			limiter.Update(tt.header)
+
+			if len(limiter.buckets) != tt.buckets {
+				t.Fatalf("buckets = %d, want %d", len(limiter.buckets), tt.buckets)
+			}
+			delay := limiter.reserve(time.Now())
+			if delay < tt.minDelay || delay > tt.maxDelay {
+				t.Errorf("delay = %v, want between %v and %v", delay, tt.minDelay, tt.maxDelay)
+			}
+		})
+	}
+}
+
+func TestRateLimiterUpdateKeepsUsedTokens(t *testing.T) {
+	limiter := NewRateLimiter("2:60")
+	now := time.Now()
+	limiter.reserve(now)
+	limiter.reserve(now)
+
+	// Те же лимиты в заголовке не должны обнулять израсходованное окно
+	limiter.Update(http.Header{"X-App-Rate-Limit": {"2:60"}})
+	if delay := limiter.reserve(time.Now()); delay < 29*time.Second {
+		t.Fatalf("delay = %v, want the drained window to be kept", delay)
+	}
+}
+
+func TestRateLimiterBlock(t *testing.T) {
+	limiter := NewRateLimiter("")
+
+	limiter.Block(time.Minute)
+	if delay := limiter.reserve(time.Now()); delay < 59*time.Second || delay > time.Minute {
+		t.Fatalf("delay = %v, want about 1m", delay)
+	}
+
+	// Более короткая пауза не сокращает текущую
+	limiter.Block(time.Second)
+	if delay := limiter.reserve(time.Now()); delay < 59*time.Second {
+		t.Fatalf("delay after shorter Block = %v, want about 1m", delay)
+	}
+
+	if delay := limiter.reserve(time.Now().Add(time.Minute)); delay != 0 {
+		t.Fatalf("delay after block = %v, want 0", delay)
+	}
+}
+
+func TestValorantClientRetryAfterBlocksLimiter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter("")
+	client := NewValorantAPIClient("", "", limiter, nil)
+
+	start := time.Now()
+	resp, err := client.fetch(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if string(resp.Body) != `{}` {
+		t.Errorf("body = %q", resp.Body)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	// Повтор ждет Retry-After через лимитер, а не короткий backoff
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("retried after %v, want Retry-After of 1s", elapsed)
+	}
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"valorant-app/models"
+)
+
+// riotQueueCompetitive очередь рейтинговых матчей Riot
+const riotQueueCompetitive = "competitive"
+
+// RiotProvider поставщик данных через официальный Riot API.
+// Аккаунты запрашиваются у региональных кластеров (europe, americas, asia), матчи — у шардов VAL (eu, na, ...).
+type RiotProvider struct {
+	Client  *ValorantAPIClient
+	BaseURL string // Если задан, все запросы идут на этот адрес вместо *.api.riotgames.com
+
+	contentMu sync.Mutex
+	content   *riotContent
+}
+
+// NewRiotProvider создает поставщика Riot API
+func NewRiotProvider(apiKey, baseURL string, limiter *RateLimiter, cache *ResponseCache) *RiotProvider {
+	return &RiotProvider{
+		Client:  NewValorantAPIClient(apiKey, "X-Riot-Token", limiter, cache),
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// riotAccountClusters региональные кластеры Riot Account API для шардов VAL
+var riotAccountClusters = map[string]string{
+	"eu":    "europe",
+	"na":    "americas",
+	"latam": "americas",
+	"br":    "americas",
+	"ap":    "asia",
+	"kr":    "asia",
+}
+
+// url собирает адрес запроса к хосту host.api.riotgames.com
+func (p *RiotProvider) url(host, requestPath string) string {
+	if p.BaseURL != "" {
+		return p.BaseURL + requestPath
+	}
+	return fmt.Sprintf("https://%s.api.riotgames.com%s", host, requestPath)
+}
+
+type riotAccount struct {
+	Puuid    string `json:"puuid"`
+	GameName string `json:"gameName"`
+	TagLine  string `json:"tagLine"`
+}
+
+type riotMatchList struct {
+	History []struct {
+		MatchID             string `json:"matchId"`
+		GameStartTimeMillis int64  `json:"gameStartTimeMillis"`
+		QueueID             string `json:"queueId"`
+	} `json:"history"`
+}
+
+type riotMatch struct {
+	MatchInfo struct {
+		MatchID          string `json:"matchId"`
+		MapID            string `json:"mapId"`
+		GameLengthMillis int    `json:"gameLengthMillis"`
+		GameStartMillis  int64  `json:"gameStartMillis"`
+		QueueID          string `json:"queueId"`
+	} `json:"matchInfo"`
+	Players []struct {
+		Puuid           string `json:"puuid"`
+		TeamID          string `json:"teamId"`
+		CharacterID     string `json:"characterId"`
+		CompetitiveTier int    `json:"competitiveTier"`
+		Stats           struct {
+			Score   int `json:"score"`
+			Kills   int `json:"kills"`
+			Deaths  int `json:"deaths"`
+			Assists int `json:"assists"`
+		} `json:"stats"`
+	} `json:"players"`
+	Teams []struct {
+		TeamID    string `json:"teamId"`
+		Won       bool   `json:"won"`
+		RoundsWon int    `json:"roundsWon"`
+	} `json:"teams"`
+	RoundResults []struct {
+		PlayerStats []struct {
+			Puuid string `json:"puuid"`
+			Kills []struct {
+				TimeSinceRoundStartMillis int    `json:"timeSinceRoundStartMillis"`
+				Killer                    string `json:"killer"`
+				Victim                    string `json:"victim"`
+			} `json:"kills"`
+			Damage []struct {
+				Damage    int `json:"damage"`
+				Headshots int `json:"headshots"`
+				Bodyshots int `json:"bodyshots"`
+				Legshots  int `json:"legshots"`
+			} `json:"damage"`
+		} `json:"playerStats"`
+	} `json:"roundResults"`
+}
+
+// riotContent справочник агентов и карт; матчи Riot ссылаются на них по ID
+type riotContent struct {
+	Characters []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"characters"`
+	Maps []struct {
+		Name      string `json:"name"`
+		AssetPath string `json:"assetPath"`
+	} `json:"maps"`
+}
+
+// GetAccount находит аккаунт по Riot ID
+func (p *RiotProvider) GetAccount(ctx context.Context, region, gameName, tag string) (*PlayerInfo, error) {
+	cluster, ok := riotAccountClusters[region]
+	if !ok {
+		cluster = riotAccountClusters["eu"]
+	}
+
+	var account riotAccount
+	requestPath := fmt.Sprintf("/riot/account/v1/accounts/by-riot-id/%s/%s", url.PathEscape(gameName), url.PathEscape(tag))
+	if err := p.Client.getJSON(ctx, CacheAccount, p.url(cluster, requestPath), &account); err != nil {
+		return nil, err
+	}
+	return &PlayerInfo{Puuid: account.Puuid, GameName: account.GameName, TagLine: account.TagLine}, nil
+}
+
+// GetMMR определяет ранг по последнему рейтинговому матчу: публичного MMR-эндпоинта у Riot нет,
+// поэтому рейтинг внутри ранга неизвестен и всегда равен 0
+func (p *RiotProvider) GetMMR(ctx context.Context, region, puuid string) (*MMRInfo, error) {
+	list, err := p.matchList(ctx, region, puuid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range list.History {
+		if entry.QueueID != riotQueueCompetitive {
+			continue
+		}
+		var match riotMatch
+		if err := p.Client.getJSON(ctx, CacheMatch, p.url(region, "/val/match/v1/matches/"+url.PathEscape(entry.MatchID)), &match); err != nil {
+			return nil, err
+		}
+		for _, player := range match.Players {
+			if player.Puuid == puuid {
+				return &MMRInfo{Tier: riotTierName(player.CompetitiveTier)}, nil
+			}
+		}
+	}
+	return &MMRInfo{Tier: models.RankTiers[0]}, nil
+}
+
+// GetMatchList возвращает ID последних count матчей
+func (p *RiotProvider) GetMatchList(ctx context.Context, region, puuid string, count int) ([]string, error) {
+	list, err := p.matchList(ctx, region, puuid)
+	if err != nil {
+		return nil, err
+	}
+
+	matchIDs := make([]string, 0, count)
+	for _, entry := range list.History {
+		if len(matchIDs) == count {
+			break
+		}
+		matchIDs = append(matchIDs, entry.MatchID)
+	}
+	return matchIDs, nil
+}
+
+// matchList история матчей аккаунта, от новых к старым
+func (p *RiotProvider) matchList(ctx context.Context, region, puuid string) (*riotMatchList, error) {
+	var list riotMatchList
+	if err := p.Client.getJSON(ctx, CacheMatchList, p.url(region, "/val/match/v1/matchlists/by-puuid/"+url.PathEscape(puuid)), &list); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(list.History, func(i, j int) bool {
+		return list.History[i].GameStartTimeMillis > list.History[j].GameStartTimeMillis
+	})
+	return &list, nil
+}
+
+// GetMatchDetails возвращает детали матча; урон, хедшоты и первые убийства считаются по раундам
+func (p *RiotProvider) GetMatchDetails(ctx context.Context, region, matchID string) (*MatchInfo, error) {
+	var match riotMatch
+	if err := p.Client.getJSON(ctx, CacheMatch, p.url(region, "/val/match/v1/matches/"+url.PathEscape(matchID)), &match); err != nil {
+		return nil, err
+	}
+	content, err := p.loadContent(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &MatchInfo{
+		MatchID:  match.MatchInfo.MatchID,
+		Map:      content.mapName(match.MatchInfo.MapID),
+		Mode:     normalizeMode(match.MatchInfo.QueueID),
+		Date:     matchDate(time.UnixMilli(match.MatchInfo.GameStartMillis)),
+		Duration: match.MatchInfo.GameLengthMillis / 1000,
+	}
+
+	won := make(map[string]bool)
+	var rounds []int
+	for _, team := range match.Teams {
+		won[team.TeamID] = team.Won
+		rounds = append(rounds, team.RoundsWon)
+	}
+	if len(rounds) == 2 {
+		info.Score = roundScore(rounds[0], rounds[1])
+	}
+
+	damage := make(map[string]int)
+	headshots := make(map[string]int)
+	bodyshots := make(map[string]int)
+	legshots := make(map[string]int)
+	kills := make([][]roundKill, 0, len(match.RoundResults))
+	for _, round := range match.RoundResults {
+		var roundKills []roundKill
+		for _, stats := range round.PlayerStats {
+			for _, hit := range stats.Damage {
+				damage[stats.Puuid] += hit.Damage
+				headshots[stats.Puuid] += hit.Headshots
+				bodyshots[stats.Puuid] += hit.Bodyshots
+				legshots[stats.Puuid] += hit.Legshots
+			}
+			for _, kill := range stats.Kills {
+				roundKills = append(roundKills, roundKill{Time: kill.TimeSinceRoundStartMillis, Killer: kill.Killer, Victim: kill.Victim})
+			}
+		}
+		kills = append(kills, roundKills)
+	}
+
+	for _, player := range match.Players {
+		info.Players = append(info.Players, MatchPlayerInfo{
+			Puuid:     player.Puuid,
+			Agent:     content.characterName(player.CharacterID),
+			Won:       won[player.TeamID],
+			Kills:     player.Stats.Kills,
+			Deaths:    player.Stats.Deaths,
+			Assists:   player.Stats.Assists,
+			Score:     player.Stats.Score,
+			Damage:    damage[player.Puuid],
+			Headshots: headshots[player.Puuid],
+			Bodyshots: bodyshots[player.Puuid],
+			Legshots:  legshots[player.Puuid],
+		})
+	}
+	applyFirstKills(info, kills)
+
+	return info, nil
+}
+
+// loadContent загружает справочник агентов и карт один раз; при ошибке API она пишется в лог,
+// а имена заменяются ID до следующей попытки. Ошибка возвращается только при отмене ctx.
+func (p *RiotProvider) loadContent(ctx context.Context, region string) (*riotContent, error) {
+	p.contentMu.Lock()
+	defer p.contentMu.Unlock()
+
+	if p.content != nil {
+		return p.content, nil
+	}
+
+	var content riotContent
+	if err := p.Client.getJSON(ctx, CacheContent, p.url(region, "/val/content/v1/contents?locale=en-US"), &content); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		log.Printf("Failed to load Valorant content for %s, using IDs instead of names: %v", region, err)
+		return &riotContent{}, nil
+	}
+	p.content = &content
+	return p.content, nil
+}
+
+func (c *riotContent) characterName(id string) string {
+	for _, character := range c.Characters {
+		if strings.EqualFold(character.ID, id) {
+			return character.Name
+		}
+	}
+	return id
+}
+
+func (c *riotContent) mapName(assetPath string) string {
+	for _, m := range c.Maps {
+		if m.AssetPath == assetPath {
+			return m.Name
+		}
+	}
+	return path.Base(assetPath)
+}
+
+// riotTierName название ранга по номеру competitiveTier: 3 — Iron 1, 27 — Radiant
+func riotTierName(tier int) string {
+	index := tier - 2
+	if index <= 0 || index >= len(models.RankTiers) {
+		return models.RankTiers[0]
+	}
+	return models.RankTiers[index]
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	fakeMatchAscent = "6f0b1b6e-8d3a-4f55-9c61-0a1f2b3c4d01"
+	fakeMatchBind   = "6f0b1b6e-8d3a-4f55-9c61-0a1f2b3c4d02"
+	fakeMatchHaven  = "6f0b1b6e-8d3a-4f55-9c61-0a1f2b3c4d03"
+
+	puuidAurora = "f1b21321-d03d-5261-8537-23ba8531d5f4"
+)
+
+func TestRiotProviderAccountAndHistory(t *testing.T) {
+	provider := newFakeRiotProvider(t)
+	ctx := context.Background()
+
+	account, err := provider.GetAccount(ctx, "eu", "Aurora", "EU1")
+	if err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+	if account.Puuid != puuidAurora || account.GameName != "Aurora" || account.TagLine != "EU1" {
+		t.Errorf("account = %+v", account)
+	}
+	if _, err := provider.GetAccount(ctx, "eu", "Nobody", "0000"); err == nil {
+		t.Error("GetAccount for unknown Riot ID succeeded")
+	}
+
+	matchIDs, err := provider.GetMatchList(ctx, "eu", puuidAurora, 2)
+	if err != nil {
+		t.Fatalf("GetMatchList: %v", err)
+	}
+	if strings.Join(matchIDs, ",") != fakeMatchHaven+","+fakeMatchBind {
+		t.Errorf("match list = %v, want the two newest matches", matchIDs)
+	}
+
+	mmr, err := provider.GetMMR(ctx, "eu", puuidAurora)
+	if err != nil {
+		t.Fatalf("GetMMR: %v", err)
+	}
+	if mmr.Tier != "Gold 3" || mmr.RankRating != 0 {
+		t.Errorf("mmr = %+v, want Gold 3 from the last competitive match", mmr)
+	}
+}
+
+func TestRiotProviderGetMatchDetails(t *testing.T) {
+	provider := newFakeRiotProvider(t)
+
+	match, err := provider.GetMatchDetails(context.Background(), "eu", fakeMatchAscent)
+	if err != nil {
+		t.Fatalf("GetMatchDetails: %v", err)
+	}
+	if match.MatchID != fakeMatchAscent || match.Map != "Ascent" || match.Mode != "competitive" ||
+		match.Score != "13-10" || match.Date != "2025-10-09T08:53:20Z" || match.Duration != 2280 {
+		t.Errorf("match = %s %s/%s %s %s %ds", match.MatchID, match.Map, match.Mode, match.Score, match.Date, match.Duration)
+	}
+	if len(match.Players) != 5 {
+		t.Fatalf("players = %d, want 5", len(match.Players))
+	}
+
+	aurora := findMatchPlayer(match, puuidAurora)
+	if aurora == nil {
+		t.Fatal("Aurora is missing from the match")
+	}
+	want := MatchPlayerInfo{
+		Puuid:      puuidAurora,
+		Agent:      "Jett",
+		Won:        true,
+		Kills:      24,
+		Deaths:     15,
+		Assists:    4,
+		Score:      5210,
+		Damage:     300,
+		Headshots:  2,
+		Bodyshots:  2,
+		FirstKills: 1,
+	}
+	if *aurora != want {
+		t.Errorf("Aurora = %+v, want %+v", *aurora, want)
+	}
+
+	// Первое убийство раунда — самое раннее по времени, а не первое в списке игроков
+	firstKills := map[string][2]int{
+		puuidAurora:                            {1, 0},
+		"8b49446a-755c-5874-af85-90f39fffb10e": {0, 0},
+		"b073be39-1840-540a-9c4d-4e66307c518e": {1, 1},
+		"c7cb1a5d-d5d3-53a0-a66c-039fd4747beb": {1, 1},
+		"87a6d249-cabf-562a-9eaf-9c1fcfd8df9a": {0, 1},
+	}
+	for _, player := range match.Players {
+		expected, ok := firstKills[player.Puuid]
+		if !ok {
+			t.Errorf("unexpected player %s", player.Puuid)
+			continue
+		}
+		if player.FirstKills != expected[0] || player.FirstDeaths != expected[1] {
+			t.Errorf("player %s first kills/deaths = %d/%d, want %d/%d",
+				player.Puuid, player.FirstKills, player.FirstDeaths, expected[0], expected[1])
+		}
+		if red := strings.HasPrefix(player.Puuid, "c7cb") || strings.HasPrefix(player.Puuid, "87a6"); player.Won == red {
+			t.Errorf("player %s won = %v", player.Puuid, player.Won)
+		}
+	}
+}
+
+func TestRiotProviderRoundScore(t *testing.T) {
+	provider := newFakeRiotProvider(t)
+
+	// Счет всегда начинается с раундов победившей команды, в какой бы стороне она ни была
+	tests := []struct {
+		matchID string
+		score   string
+		won     bool
+	}{
+		{fakeMatchAscent, "13-10", true},
+		{fakeMatchBind, "13-9", false},
+		{fakeMatchHaven, "13-11", true},
+	}
+	for _, tt := range tests {
+		match, err := provider.GetMatchDetails(context.Background(), "eu", tt.matchID)
+		if err != nil {
+			t.Fatalf("GetMatchDetails(%s): %v", tt.matchID, err)
+		}
+		if match.Score != tt.score {
+			t.Errorf("match %s score = %q, want %q", tt.matchID, match.Score, tt.score)
+		}
+		if aurora := findMatchPlayer(match, puuidAurora); aurora == nil || aurora.Won != tt.won {
+			t.Errorf("match %s Aurora = %+v, want won = %v", tt.matchID, aurora, tt.won)
+		}
+	}
+}
+
+func TestRiotProviderWithoutContent(t *testing.T) {
+	fake := newFakeRiotServer(t)
+	provider := newRiotProviderFor(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/val/content/") {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	provider.Client.MaxRetries = 0
+
+	// Без справочника матч загружается, а вместо имен остаются ID
+	match, err := provider.GetMatchDetails(context.Background(), "eu", fakeMatchAscent)
+	if err != nil {
+		t.Fatalf("GetMatchDetails: %v", err)
+	}
+	if match.Map != "Ascent" {
+		t.Errorf("map = %q, want the asset name", match.Map)
+	}
+	if aurora := findMatchPlayer(match, puuidAurora); aurora == nil || aurora.Agent != "add6443a-41bd-e414-f6ad-e58d267f4e95" {
+		t.Errorf("Aurora = %+v, want the character ID as agent", aurora)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.GetMatchDetails(ctx, "eu", fakeMatchBind); err != context.Canceled {
+		t.Errorf("GetMatchDetails with cancelled ctx = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Package valorantfake — фейковый Riot API на фикстурах для разработки и тестов без сети.
+// Отвечает на те же маршруты, что и Riot API, поэтому с ним работает обычный RiotProvider.
+package valorantfake
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Fixtures фикстуры по умолчанию: accounts.json, content.json и matches/*.json в формате Riot API
+//
+//go:embed fixtures
+var Fixtures embed.FS
+
+// Server фейковый Riot API: аккаунты, история матчей, детали матчей и справочник контента
+type Server struct {
+	accounts []account
+	content  json.RawMessage
+	matches  map[string]json.RawMessage
+	history  map[string][]historyEntry // История матчей по puuid, от новых к старым
+	mux      *http.ServeMux
+}
+
+type account struct {
+	Puuid    string `json:"puuid"`
+	GameName string `json:"gameName"`
+	TagLine  string `json:"tagLine"`
+}
+
+type historyEntry struct {
+	MatchID             string `json:"matchId"`
+	GameStartTimeMillis int64  `json:"gameStartTimeMillis"`
+	QueueID             string `json:"queueId"`
+}
+
+// matchSummary поля матча, по которым строится история игроков
+type matchSummary struct {
+	MatchInfo struct {
+		MatchID         string `json:"matchId"`
+		GameStartMillis int64  `json:"gameStartMillis"`
+		QueueID         string `json:"queueId"`
+	} `json:"matchInfo"`
+	Players []struct {
+		Puuid string `json:"puuid"`
+	} `json:"players"`
+}
+
+// NewServer загружает фикстуры из fixtures (корень с accounts.json, content.json и matches/)
+func NewServer(fixtures fs.FS) (*Server, error) {
+	s := &Server{
+		matches: make(map[string]json.RawMessage),
+		history: make(map[string][]historyEntry),
+		mux:     http.NewServeMux(),
+	}
+
+	data, err := fs.ReadFile(fixtures, "accounts.json")
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.accounts); err != nil {
+		return nil, fmt.Errorf("accounts.json: %w", err)
+	}
+
+	if s.content, err = fs.ReadFile(fixtures, "content.json"); err != nil {
+		return nil, err
+	}
+
+	files, err := fs.Glob(fixtures, "matches/*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fixtures, file)
+		if err != nil {
+			return nil, err
+		}
+		var match matchSummary
+		if err := json.Unmarshal(data, &match); err != nil {
+			return nil, fmt.Errorf("%s: %w", path.Base(file), err)
+		}
+
+		s.matches[match.MatchInfo.MatchID] = data
+		entry := historyEntry{
+			MatchID:             match.MatchInfo.MatchID,
+			GameStartTimeMillis: match.MatchInfo.GameStartMillis,
+			QueueID:             match.MatchInfo.QueueID,
+		}
+		for _, player := range match.Players {
+			s.history[player.Puuid] = append(s.history[player.Puuid], entry)
+		}
+	}
+	for puuid := range s.history {
+		entries := s.history[puuid]
+		sort.Slice(entries, func(i, j int) bool { return entries[i].GameStartTimeMillis > entries[j].GameStartTimeMillis })
+	}
+
+	s.mux.HandleFunc("GET /riot/account/v1/accounts/by-riot-id/{name}/{tag}", s.getAccount)
+	s.mux.HandleFunc("GET /val/match/v1/matchlists/by-puuid/{puuid}", s.getMatchList)
+	s.mux.HandleFunc("GET /val/match/v1/matches/{id}", s.getMatch)
+	s.mux.HandleFunc("GET /val/content/v1/contents", s.getContent)
+	return s, nil
+}
+
+// ServeHTTP отвечает на запросы в формате Riot API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start запускает фейковый API с фикстурами по умолчанию на addr (пустой — свободный порт на localhost)
+// и возвращает его базовый URL
+func Start(addr string) (string, error) {
+	fixtures, err := fs.Sub(Fixtures, "fixtures")
+	if err != nil {
+		return "", err
+	}
+	server, err := NewServer(fixtures)
+	if err != nil {
+		return "", err
+	}
+
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		if err := http.Serve(listener, server); err != nil {
+			log.Printf("Fake Valorant API stopped: %v", err)
+		}
+	}()
+	return "http://" + listener.Addr().String(), nil
+}
+
+func (s *Server) getAccount(w http.ResponseWriter, r *http.Request) {
+	name, tag := r.PathValue("name"), r.PathValue("tag")
+	for _, account := range s.accounts {
+		if strings.EqualFold(account.GameName, name) && strings.EqualFold(account.TagLine, tag) {
+			writeJSON(w, account)
+			return
+		}
+	}
+	writeNotFound(w)
+}
+
+func (s *Server) getMatchList(w http.ResponseWriter, r *http.Request) {
+	puuid := r.PathValue("puuid")
+	history := s.history[puuid]
+	if history == nil {
+		if !s.hasAccount(puuid) {
+			writeNotFound(w)
+			return
+		}
+		history = []historyEntry{}
+	}
+	writeJSON(w, map[string]interface{}{"puuid": puuid, "history": history})
+}
+
+func (s *Server) getMatch(w http.ResponseWriter, r *http.Request) {
+	match, ok := s.matches[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeRaw(w, match)
+}
+
+func (s *Server) getContent(w http.ResponseWriter, r *http.Request) {
+	writeRaw(w, s.content)
+}
+
+func (s *Server) hasAccount(puuid string) bool {
+	for _, account := range s.accounts {
+		if account.Puuid == puuid {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeRaw(w, data)
+}
+
+func writeRaw(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeNotFound ответ в формате ошибок Riot API
+func writeNotFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"status":{"message":"Data not found","status_code":404}}`))
+}
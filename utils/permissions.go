@@ -1,14 +1,33 @@
 package utils
 
 import (
+	"math"
 	"valorant-app/database"
 	"valorant-app/models"
 )
 
-// CheckPermission проверяет, есть ли у пользователя определенное право в команде
+// IsTeamMember проверяет, состоит ли пользователь в команде
+func IsTeamMember(userID, teamID uint) bool {
+	var count int64
+	database.DB.Model(&models.TeamMembership{}).Where("user_id = ? AND team_id = ?", userID, teamID).Count(&count)
+	return count > 0
+}
+
+// CheckPermission проверяет, есть ли у пользователя определенное право в команде.
+// Учитываются роли команды и роль в организации, которой принадлежит команда.
 func CheckPermission(userID, teamID uint, permissionName string) bool {
+	for _, permission := range models.OrgRolePermissions(OrganizationRole(userID, teamID)) {
+		if permission == permissionName {
+			return true
+		}
+	}
+
+	if !IsTeamMember(userID, teamID) {
+		return false
+	}
+
 	var user models.User
-	result := database.DB.Preload("Roles.Permissions").Where("id = ? AND team_id = ?", userID, teamID).First(&user)
+	result := database.DB.Preload("Roles.Permissions").First(&user, userID)
 	if result.Error != nil {
 		return false
 	}
@@ -29,8 +48,12 @@ func CheckPermission(userID, teamID uint, permissionName string) bool {
 
 // HasRole проверяет, есть ли у пользователя определенная роль в команде
 func HasRole(userID, teamID uint, roleName string) bool {
+	if !IsTeamMember(userID, teamID) {
+		return false
+	}
+
 	var user models.User
-	result := database.DB.Preload("Roles").Where("id = ? AND team_id = ?", userID, teamID).First(&user)
+	result := database.DB.Preload("Roles").First(&user, userID)
 	if result.Error != nil {
 		return false
 	}
@@ -51,15 +74,18 @@ func IsTeamOwner(userID, teamID uint) bool {
 	return result.Error == nil
 }
 
-// GetUserPermissions получает все права пользователя в команде
+// GetUserPermissions получает все права пользователя в команде, включая права роли в организации
 func GetUserPermissions(userID, teamID uint) []string {
+	permissions := make(map[string]bool)
+	for _, permission := range models.OrgRolePermissions(OrganizationRole(userID, teamID)) {
+		permissions[permission] = true
+	}
+
 	var user models.User
-	result := database.DB.Preload("Roles.Permissions").Where("id = ? AND team_id = ?", userID, teamID).First(&user)
-	if result.Error != nil {
-		return []string{}
+	if IsTeamMember(userID, teamID) {
+		database.DB.Preload("Roles.Permissions").First(&user, userID)
 	}
 
-	permissions := make(map[string]bool)
 	for _, role := range user.Roles {
 		if role.TeamID != teamID {
 			continue
@@ -76,3 +102,76 @@ func GetUserPermissions(userID, teamID uint) []string {
 
 	return resultPermissions
 }
+
+// HasTeamPermission проверяет право пользователя в команде; владелец команды имеет все права
+func HasTeamPermission(userID, teamID uint, permissionName string) bool {
+	return IsTeamOwner(userID, teamID) || CheckPermission(userID, teamID, permissionName)
+}
+
+// OwnerRoleLevel уровень владельца команды: выше любой роли
+const OwnerRoleLevel = math.MaxInt32
+
+// OrgManagerRoleLevel уровень владельца и менеджера организации в ее командах:
+// выше любой роли команды, но ниже владельца команды
+const OrgManagerRoleLevel = OwnerRoleLevel - 1
+
+// OrganizationRole возвращает роль пользователя в организации, которой принадлежит команда.
+// Создатель организации — models.OrgRoleOwner; пустая строка — роли нет или команда вне организации.
+func OrganizationRole(userID, teamID uint) string {
+	var team models.Team
+	result := database.DB.Select("id", "organization_id").First(&team, teamID)
+	if result.Error != nil || team.OrganizationID == nil {
+		return ""
+	}
+
+	return OrganizationMemberRole(userID, *team.OrganizationID)
+}
+
+// OrganizationMemberRole возвращает роль пользователя в организации; создатель — models.OrgRoleOwner
+func OrganizationMemberRole(userID, organizationID uint) string {
+	var organization models.Organization
+	if err := database.DB.First(&organization, organizationID).Error; err != nil {
+		return ""
+	}
+	if organization.CreatedBy == userID {
+		return models.OrgRoleOwner
+	}
+
+	var member models.OrganizationMember
+	result := database.DB.Where("organization_id = ? AND user_id = ?", organization.ID, userID).First(&member)
+	if result.Error != nil {
+		return ""
+	}
+	return member.Role
+}
+
+// TeamRoleLevel возвращает позицию старшей роли пользователя в команде.
+// Владелец команды имеет уровень OwnerRoleLevel, владелец и менеджер организации — OrgManagerRoleLevel,
+// пользователь без ролей — -1.
+func TeamRoleLevel(userID, teamID uint) int {
+	if IsTeamOwner(userID, teamID) {
+		return OwnerRoleLevel
+	}
+	switch OrganizationRole(userID, teamID) {
+	case models.OrgRoleOwner, models.OrgRoleManager:
+		return OrgManagerRoleLevel
+	}
+
+	if !IsTeamMember(userID, teamID) {
+		return -1
+	}
+
+	var user models.User
+	result := database.DB.Preload("Roles").First(&user, userID)
+	if result.Error != nil {
+		return -1
+	}
+
+	level := -1
+	for _, role := range user.Roles {
+		if role.TeamID == teamID && role.Position > level {
+			level = role.Position
+		}
+	}
+	return level
+}
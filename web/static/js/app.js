@@ -12,12 +12,13 @@ let currentUser = null;
 // API base URL
 const API_BASE = '/api';
 
-// Добавляем заголовок для обхода предупреждения ngrok
+// Добавляем заголовок для обхода предупреждения ngrok и подписанные initData для авторизации
 const originalFetch = window.fetch;
 window.fetch = function(url, options = {}) {
     options.headers = {
         ...options.headers,
-        'ngrok-skip-browser-warning': 'true'
+        'ngrok-skip-browser-warning': 'true',
+        'Authorization': `tma ${tg.initData}`
     };
     return originalFetch(url, options);
 };
@@ -50,41 +51,33 @@ async function createOrGetUser() {
     if (!user) return;
     
     try {
-        // Try to get existing user
-        const response = await fetch(`${API_BASE}/users/${user.id}`);
+        // Пользователь регистрируется автоматически при первом запросе с initData
+        const response = await fetch(`${API_BASE}/me`);
         if (response.ok) {
             currentUser = await response.json();
-        } else {
-            // Create new user
-            const userData = {
-                telegram_id: user.id,
-                username: user.username || '',
-                first_name: user.first_name || '',
-                last_name: user.last_name || ''
-            };
-            
-            const createResponse = await fetch(`${API_BASE}/users`, {
-                method: 'POST',
-                headers: {
-                    'Content-Type': 'application/json'
-                },
-                body: JSON.stringify(userData)
-            });
-            
-            if (createResponse.ok) {
-                currentUser = await createResponse.json();
-            }
         }
     } catch (error) {
         console.error('Error creating/getting user:', error);
     }
 }
 
-async function loadTeams() {
+// Список команд загружается страницами по курсору
+let teams = [];
+let teamsCursor = null;
+let teamsSearchTimer = null;
+
+async function loadTeams(cursor = null) {
+    const params = new URLSearchParams();
+    const search = document.getElementById('teams-search').value.trim();
+    if (search) params.set('q', search);
+    if (cursor) params.set('cursor', cursor);
+    
     try {
-        const response = await fetch(`${API_BASE}/teams`);
+        const response = await fetch(`${API_BASE}/teams?${params}`);
         if (response.ok) {
-            const teams = await response.json();
+            const page = await response.json();
+            teams = cursor ? teams.concat(page.teams) : page.teams;
+            teamsCursor = page.next_cursor;
             displayTeams(teams);
         } else {
             showError('Ошибка загрузки команд');
@@ -95,8 +88,20 @@ async function loadTeams() {
     }
 }
 
+function loadMoreTeams() {
+    if (teamsCursor) {
+        loadTeams(teamsCursor);
+    }
+}
+
+function searchTeams() {
+    clearTimeout(teamsSearchTimer);
+    teamsSearchTimer = setTimeout(() => loadTeams(), 300);
+}
+
 function displayTeams(teams) {
     const teamsList = document.getElementById('teams-list');
+    document.getElementById('teams-more').style.display = teamsCursor ? '' : 'none';
     
     if (teams.length === 0) {
         teamsList.innerHTML = '<div class="loading">Команды не найдены</div>';
@@ -108,7 +113,7 @@ function displayTeams(teams) {
             <div class="team-name">${team.name}</div>
             <div class="team-description">${team.description || 'Нет описания'}</div>
             <div class="team-meta">
-                <span>Участников: ${team.members ? team.members.length : 0}</span>
+                <span>Участников: ${team.member_count}${team.region ? ` · ${team.region.toUpperCase()}` : ''}${team.average_rank_tier ? ` · ${team.average_rank_tier}` : ''}${team.recruiting ? ' · идет набор' : ''}</span>
                 <div class="team-actions">
                     ${getTeamActionButton(team)}
                 </div>
@@ -120,31 +125,43 @@ function displayTeams(teams) {
 function getTeamActionButton(team) {
     if (!currentUser) return '';
     
-    const isInTeam = currentUser.team_id === team.id;
-    const isMyTeam = currentUser.team_id === team.id;
+    const isInTeam = isTeamMember(team.id);
     
     if (isInTeam) {
-        return `<button class="btn btn-danger" onclick="leaveTeam()">Покинуть команду</button>`;
+        return `<button class="btn btn-danger" onclick="leaveTeam(${team.id})">Покинуть команду</button>`;
     } else {
         return `<button class="btn btn-success" onclick="joinTeam(${team.id})">Вступить в команду</button>`;
     }
 }
 
+// Пользователь может состоять в нескольких командах
+function isTeamMember(teamId) {
+    return Boolean(currentUser && currentUser.teams && currentUser.teams.some(team => team.id === teamId));
+}
+
 async function joinTeam(teamId) {
     if (!currentUser) {
         showError('Пользователь не найден');
         return;
     }
     
+    // Вступить можно только по коду приглашения
+    const token = prompt('Введите код приглашения');
+    if (!token) return;
+    
     try {
-        const response = await fetch(`${API_BASE}/teams/${teamId}/join/${currentUser.telegram_id}`, {
-            method: 'POST'
+        const response = await fetch(`${API_BASE}/teams/${teamId}/join`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json'
+            },
+            body: JSON.stringify({ token: token.trim() })
         });
         
         if (response.ok) {
             showSuccess('Вы успешно вступили в команду!');
-            await loadTeams();
             await loadMyTeam();
+            await loadTeams();
         } else {
             const error = await response.json();
             showError(error.error || 'Ошибка вступления в команду');
@@ -155,21 +172,21 @@ async function joinTeam(teamId) {
     }
 }
 
-async function leaveTeam() {
+async function leaveTeam(teamId) {
     if (!currentUser) {
         showError('Пользователь не найден');
         return;
     }
     
     try {
-        const response = await fetch(`${API_BASE}/teams/leave/${currentUser.telegram_id}`, {
+        const response = await fetch(`${API_BASE}/teams/${teamId}/leave`, {
             method: 'POST'
         });
         
         if (response.ok) {
             showSuccess('Вы покинули команду');
-            await loadTeams();
             await loadMyTeam();
+            await loadTeams();
         } else {
             const error = await response.json();
             showError(error.error || 'Ошибка выхода из команды');
@@ -181,17 +198,23 @@ async function leaveTeam() {
 }
 
 async function loadMyTeam() {
-    if (!currentUser || !currentUser.team_id) {
+    // Список команд пользователя мог измениться после вступления или выхода
+    await createOrGetUser();
+    
+    if (!currentUser || !currentUser.teams || currentUser.teams.length === 0) {
         document.getElementById('my-team-content').innerHTML = 
             '<div class="loading">Вы не состоите ни в одной команде</div>';
         return;
     }
     
     try {
-        const response = await fetch(`${API_BASE}/teams/${currentUser.team_id}`);
-        if (response.ok) {
-            const team = await response.json();
-            displayMyTeam(team);
+        const responses = await Promise.all(currentUser.teams.map(team => fetch(`${API_BASE}/teams/${team.id}`)));
+        const rosterResponses = await Promise.all(currentUser.teams.map(team => fetch(`${API_BASE}/teams/${team.id}/roster`)));
+        if (responses.every(response => response.ok) && rosterResponses.every(response => response.ok)) {
+            const teams = await Promise.all(responses.map(response => response.json()));
+            const rosters = await Promise.all(rosterResponses.map(response => response.json()));
+            teams.forEach((team, i) => { team.roster = rosters[i]; });
+            displayMyTeams(teams);
         } else {
             document.getElementById('my-team-content').innerHTML = 
                 '<div class="loading">Ошибка загрузки команды</div>';
@@ -203,27 +226,49 @@ async function loadMyTeam() {
     }
 }
 
-function displayMyTeam(team) {
+function displayMyTeams(teams) {
     const myTeamContent = document.getElementById('my-team-content');
     
-    myTeamContent.innerHTML = `
+    myTeamContent.innerHTML = teams.map(team => `
         <div class="team-card">
             <div class="team-name">${team.name}</div>
             <div class="team-description">${team.description || 'Нет описания'}</div>
             <div class="team-meta">
                 <span>Участников: ${team.members ? team.members.length : 0}</span>
-                <button class="btn btn-danger" onclick="leaveTeam()">Покинуть команду</button>
+                <button class="btn btn-danger" onclick="leaveTeam(${team.id})">Покинуть команду</button>
             </div>
-            ${team.members && team.members.length > 0 ? `
-                <div class="team-members">
-                    <h4>Участники:</h4>
-                    <ul>
-                        ${team.members.map(member => `
-                            <li>${member.first_name} ${member.last_name || ''} (@${member.username || 'no_username'})</li>
-                        `).join('')}
-                    </ul>
-                </div>
-            ` : ''}
+            ${displayRosterSection(`Основной состав (${team.roster.starters.length}/${team.roster.max_starters})`, team.roster.starters)}
+            ${displayRosterSection('Запасные', team.roster.substitutes)}
+            ${displayRosterSection('Штаб', team.roster.staff)}
+        </div>
+    `).join('');
+}
+
+const rosterSlotNames = {
+    coach: 'тренер',
+    analyst: 'аналитик',
+    manager: 'менеджер'
+};
+
+// Раздел состава: игроки с игровой ролью и агентами, штаб — с позицией
+function displayRosterSection(title, memberships) {
+    if (!memberships || memberships.length === 0) {
+        return '';
+    }
+    
+    return `
+        <div class="team-members">
+            <h4>${title}:</h4>
+            <ul>
+                ${memberships.map(membership => {
+                    const member = membership.user || {};
+                    const details = rosterSlotNames[membership.roster_slot] ||
+                        [membership.in_game_role, (membership.main_agents || []).join(', ')].filter(Boolean).join(': ');
+                    return `
+                        <li>${member.first_name} ${member.last_name || ''} (@${member.username || 'no_username'})${details ? ` — ${details}` : ''}</li>
+                    `;
+                }).join('')}
+            </ul>
         </div>
     `;
 }
@@ -267,8 +312,7 @@ document.getElementById('create-team-form').addEventListener('submit', async fun
     const formData = new FormData(e.target);
     const teamData = {
         name: formData.get('name'),
-        description: formData.get('description'),
-        created_by: currentUser ? currentUser.id : null
+        description: formData.get('description')
     };
     
     try {